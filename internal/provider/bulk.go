@@ -0,0 +1,96 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// buildNameID creates the ID of a resource managing several records at one name
+func buildNameID(server, zone, name string) string {
+	return fmt.Sprintf("%s/%s/%s", server, idComponent(zone), idComponent(name))
+}
+
+// parseNameID extracts components from a server/zone/name resource ID
+func parseNameID(id string) (server, zone, name string, err error) {
+	parts := strings.SplitN(id, "/", 3)
+	if len(parts) != 3 || strings.Contains(parts[2], "/") {
+		return "", "", "", fmt.Errorf("invalid ID format: %s (expected server/zone/name, with a slash in the zone or name written as \\047)", id)
+	}
+	return parts[0], fromIDComponent(parts[1]), fromIDComponent(parts[2]), nil
+}
+
+// applyRecordChanges creates adds before deleting removes, so the name never
+// resolves to nothing while a set of records is reshuffled
+func applyRecordChanges(c *SambaClient, adds, removes []DNSRecord) error {
+	for _, r := range adds {
+		if err := c.CreateRecord(r); err != nil {
+			return fmt.Errorf("failed to create %s %s %s: %w", r.Name, r.Type, r.Value, err)
+		}
+	}
+	for _, r := range removes {
+		if err := c.DeleteRecord(r); err != nil {
+			return fmt.Errorf("failed to delete %s %s %s: %w", r.Name, r.Type, r.Value, err)
+		}
+	}
+	return nil
+}
+
+// stringSetDiff returns the elements only in newSet and only in oldSet
+func stringSetDiff(oldSet, newSet []string) (added, removed []string) {
+	oldIndex := make(map[string]bool, len(oldSet))
+	for _, v := range oldSet {
+		oldIndex[v] = true
+	}
+	newIndex := make(map[string]bool, len(newSet))
+	for _, v := range newSet {
+		newIndex[v] = true
+		if !oldIndex[v] {
+			added = append(added, v)
+		}
+	}
+	for _, v := range oldSet {
+		if !newIndex[v] {
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
+
+// setToStrings converts a schema set or list of strings to a slice
+func setToStrings(raw []interface{}) []string {
+	out := make([]string, 0, len(raw))
+	for _, v := range raw {
+		out = append(out, v.(string))
+	}
+	return out
+}
+
+// maxZoneRecordsSchema returns the optional zone size guardrail shared by bulk resources
+func maxZoneRecordsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:         schema.TypeInt,
+		Optional:     true,
+		ValidateFunc: validation.IntAtLeast(1),
+		Description:  "Abort before writing when the change would grow the zone past this many records, guarding small DCs against runaway `for_each` expansions. Counting enumerates the zone, so it costs a zone walk per apply.",
+	}
+}
+
+// checkZoneQuota fails when adding growth records would push the zone past max_zone_records
+func checkZoneQuota(d *schema.ResourceData, c *SambaClient, server, zone string, growth int) error {
+	limit := d.Get("max_zone_records").(int)
+	if limit == 0 || growth <= 0 {
+		return nil
+	}
+
+	records, err := c.ListZoneRecords(server, zone)
+	if err != nil {
+		return fmt.Errorf("failed to count zone records: %w", err)
+	}
+	if len(records)+growth > limit {
+		return fmt.Errorf("zone %s has %d records; adding %d would exceed max_zone_records (%d)", zone, len(records), growth, limit)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// unknownValue stands for a value that is only known after apply
+const unknownValue = "(known after apply)"
+
+// plannedChange is one entry of the change summary
+type plannedChange struct {
+	Action   string   `json:"action"`
+	Resource string   `json:"resource"`
+	Server   string   `json:"dns_server"`
+	Zone     string   `json:"zone"`
+	Name     string   `json:"name"`
+	Type     string   `json:"type"`
+	Replaces string   `json:"replaces,omitempty"`
+	Before   []string `json:"before,omitempty"`
+	After    []string `json:"after,omitempty"`
+}
+
+// changeSummary collects the record changes planned in this provider run into a JSON file
+// The file is rewritten whole after every change, so it is complete whenever Terraform stops.
+// Terraform plans again during apply, which overwrites entries with the same content
+type changeSummary struct {
+	path    string
+	mu      sync.Mutex
+	changes map[string]plannedChange
+}
+
+// newChangeSummary starts an empty summary at path, replacing the previous run's
+func newChangeSummary(path string) (*changeSummary, error) {
+	s := &changeSummary{path: path, changes: make(map[string]plannedChange)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.write(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *changeSummary) add(c plannedChange) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := fmt.Sprintf("%s/%s/%s/%s/%s", c.Resource, c.Server, c.Zone, c.Name, c.Type)
+	// A replacement is diffed a second time as a create of the new resource
+	if c.Action == "create" && s.changes[key].Action == "replace" {
+		return nil
+	}
+	s.changes[key] = c
+	return s.write()
+}
+
+func (s *changeSummary) write() error {
+	keys := make([]string, 0, len(s.changes))
+	for k := range s.changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	doc := struct {
+		Changes []plannedChange `json:"changes"`
+	}{Changes: make([]plannedChange, 0, len(keys))}
+	for _, k := range keys {
+		doc.Changes = append(doc.Changes, s.changes[k])
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write change summary: %w", err)
+	}
+	return nil
+}
+
+// summarizeChange returns a CustomizeDiff that adds a resource's planned change to the change summary
+// valueKey names the value attribute: a string, a list or a set of values. renameKeys are identifying
+// attributes the resource changes in place, which are summarized as a rename rather than a replace
+// Destroy plans never reach CustomizeDiff, so pure deletes are not summarized
+func summarizeChange(resource, valueKey string, renameKeys ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		if m == nil || m.(*apiClient).changes == nil {
+			return nil
+		}
+
+		// The identifying attributes are compared as DNS compares names; their StateFuncs have not run yet
+		replaced, renamed := false, false
+		for _, k := range []string{"dns_server", "zone", "name", "type"} {
+			o, n := d.GetChange(k)
+			if strings.EqualFold(o.(string), n.(string)) && d.NewValueKnown(k) {
+				continue
+			}
+			renamed = true
+			inPlace := false
+			for _, rk := range renameKeys {
+				inPlace = inPlace || rk == k
+			}
+			replaced = replaced || !inPlace
+		}
+
+		recordType := strings.ToUpper(d.Get("type").(string))
+		oldRaw, newRaw := d.GetChange(valueKey)
+
+		action := "update"
+		switch {
+		case d.Id() == "":
+			action = "create"
+		case replaced:
+			action = "replace"
+		case renamed:
+			action = "rename"
+		case d.NewValueKnown(valueKey) && recordSetsEqual(recordType, summaryValues(oldRaw), summaryValues(newRaw)):
+			// Diff suppression has not been applied to the ResourceDiff either
+			return nil
+		}
+
+		change := plannedChange{
+			Action:   action,
+			Resource: resource,
+			Server:   d.Get("dns_server").(string),
+			Zone:     d.Get("zone").(string),
+			Name:     d.Get("name").(string),
+			Type:     recordType,
+			After:    []string{unknownValue},
+		}
+		if action != "create" {
+			change.Before = summaryValues(oldRaw)
+		}
+		if action == "replace" || action == "rename" {
+			change.Replaces = d.Id()
+		}
+		if d.NewValueKnown(valueKey) {
+			change.After = summaryValues(newRaw)
+		}
+		return m.(*apiClient).changes.add(change)
+	}
+}
+
+// summaryValues converts a value attribute to a list of values
+func summaryValues(raw interface{}) []string {
+	switch v := raw.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []interface{}:
+		return setToStrings(v)
+	case *schema.Set:
+		return setToStrings(v.List())
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package provider
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// cliCommands are the subcommands RunCLI handles, in the order usage lists them
+var cliCommands = []struct {
+	name, summary string
+	run           func(c *SambaClient, opts cliOptions, stdout io.Writer) error
+}{
+	{"query", "print the records at a name, optionally of one type", cliQuery},
+	{"add", "create a record", cliAdd},
+	{"delete", "delete a record", cliDelete},
+	{"export", "print every record in a zone", cliExport},
+}
+
+// IsCLICommand reports whether a program argument names a RunCLI subcommand
+func IsCLICommand(arg string) bool {
+	for _, cmd := range cliCommands {
+		if cmd.name == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// cliOptions are the flags shared by the subcommands
+type cliOptions struct {
+	server, zone, name, recordType, value string
+	format                                string
+}
+
+// RunCLI runs a one-off operation through the same client the provider uses, returning the exit status
+// Credentials come from SAMBADNS_USERNAME and SAMBADNS_PASSWORD, and SAMBADNS_REPLAY_DIR and
+// SAMBADNS_RECORD_DIR apply as they do for the provider, so results can be compared with a plan
+func RunCLI(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || !IsCLICommand(args[0]) {
+		cliUsage(stderr)
+		return 2
+	}
+	name := args[0]
+
+	var opts cliOptions
+	var backendFile string
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&opts.server, "server", "", "DNS server (DC) to run against")
+	flags.StringVar(&opts.zone, "zone", "", "zone name")
+	if name != "export" {
+		flags.StringVar(&opts.name, "name", "", "record name relative to the zone, @ for the apex")
+		flags.StringVar(&opts.recordType, "type", "", "record type, e.g. A or CNAME")
+	}
+	if name == "add" || name == "delete" {
+		flags.StringVar(&opts.value, "value", "", "record value, in the format of sambadns_record's value")
+	}
+	if name == "query" || name == "export" {
+		flags.StringVar(&opts.format, "format", "text", "output format: text, or json in the backend_file format")
+	}
+	flags.StringVar(&backendFile, "backend-file", "", "run against a file backend instead of samba-tool")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	if err := opts.check(name); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return 2
+	}
+
+	c, err := clientFromEnv(backendFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v, or -backend-file given\n", name, err)
+		return 2
+	}
+	if err := checkCommandAvailable(c.Command, c.runner); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", name, err)
+		return 1
+	}
+
+	for _, cmd := range cliCommands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(c, opts, stdout); err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", name, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+// clientFromEnv builds a client for the command line from SAMBADNS_USERNAME and SAMBADNS_PASSWORD,
+// or for the file backend at backendFile when it is set
+func clientFromEnv(backendFile string) (*SambaClient, error) {
+	c := NewSambaClient(os.Getenv("SAMBADNS_USERNAME"), os.Getenv("SAMBADNS_PASSWORD"))
+	if backendFile != "" {
+		c.runner = &fileRunner{path: backendFile}
+		return c, nil
+	}
+	if c.Username == "" || c.Password == "" {
+		return nil, fmt.Errorf("SAMBADNS_USERNAME and SAMBADNS_PASSWORD must be set")
+	}
+	return c, nil
+}
+
+// check rejects missing or invalid flags for a subcommand
+func (o *cliOptions) check(command string) error {
+	var missing []string
+	for _, f := range []struct{ flag, value string }{{"server", o.server}, {"zone", o.zone}} {
+		if f.value == "" {
+			missing = append(missing, "-"+f.flag)
+		}
+	}
+	if command != "export" && o.name == "" {
+		missing = append(missing, "-name")
+	}
+	if command == "add" || command == "delete" {
+		if o.recordType == "" {
+			missing = append(missing, "-type")
+		}
+		if o.value == "" {
+			missing = append(missing, "-value")
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s required", strings.Join(missing, ", "))
+	}
+	if o.format != "" && o.format != "text" && o.format != "json" {
+		return fmt.Errorf("-format must be text or json, not %q", o.format)
+	}
+	o.recordType = strings.ToUpper(o.recordType)
+	return nil
+}
+
+func cliUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: terraform-provider-sambadns <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without a command the provider plugin is served to Terraform. Commands:")
+	for _, cmd := range cliCommands {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run a command with -h for its flags. Credentials are read from SAMBADNS_USERNAME and SAMBADNS_PASSWORD.")
+}
+
+func cliQuery(c *SambaClient, opts cliOptions, stdout io.Writer) error {
+	records, err := c.QueryName(opts.server, opts.zone, opts.name)
+	if err != nil {
+		return err
+	}
+	if opts.recordType != "" {
+		var matching []DNSRecord
+		for _, r := range records {
+			if r.Type == opts.recordType {
+				matching = append(matching, r)
+			}
+		}
+		records = matching
+	}
+	if len(records) == 0 {
+		what := "records"
+		if opts.recordType != "" {
+			what = opts.recordType + " records"
+		}
+		return fmt.Errorf("no %s at %s", what, joinFQDN(opts.name, opts.zone))
+	}
+	return writeCLIRecords(stdout, opts, "", records)
+}
+
+func cliAdd(c *SambaClient, opts cliOptions, stdout io.Writer) error {
+	r := DNSRecord{Server: opts.server, Zone: opts.zone, Name: opts.name, Type: opts.recordType, Value: opts.value}
+	if err := c.CreateRecord(r); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "added %s %s %s\n", joinFQDN(r.Name, r.Zone), r.Type, r.Value)
+	return nil
+}
+
+func cliDelete(c *SambaClient, opts cliOptions, stdout io.Writer) error {
+	r := DNSRecord{Server: opts.server, Zone: opts.zone, Name: opts.name, Type: opts.recordType, Value: opts.value}
+	// Like a destroy, deleting a record that does not exist succeeds
+	if err := c.DeleteRecord(r); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "deleted %s %s %s\n", joinFQDN(r.Name, r.Zone), r.Type, r.Value)
+	return nil
+}
+
+func cliExport(c *SambaClient, opts cliOptions, stdout io.Writer) error {
+	records, err := c.ListZoneRecords(opts.server, opts.zone)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Name != records[j].Name {
+			return records[i].Name < records[j].Name
+		}
+		return records[i].Type < records[j].Type
+	})
+
+	partition := ""
+	if opts.format == "json" {
+		if info, err := c.ZoneInfo(opts.server, opts.zone); err == nil {
+			partition = partitionFromZoneInfo(info)
+		}
+	}
+	return writeCLIRecords(stdout, opts, partition, records)
+}
+
+// writeCLIRecords prints records as an aligned table, or as a backend_file document for -format json
+func writeCLIRecords(w io.Writer, opts cliOptions, partition string, records []DNSRecord) error {
+	if opts.format == "json" {
+		zone := &fileZone{Partition: partition, Records: []fileRecord{}}
+		for _, r := range records {
+			record := fileRecord{Name: r.Name, Type: r.Type, Value: r.Value, TTL: r.TTL}
+			if !r.Timestamp.IsZero() {
+				record.Timestamp = r.Timestamp.Format(time.RFC3339)
+			}
+			zone.Records = append(zone.Records, record)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(fileState{Zones: map[string]*fileZone{opts.zone: zone}})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tTTL\tVALUE")
+	for _, r := range records {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", r.Name, r.Type, r.TTL, r.Value)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// clockSkewMarkers are samba-tool error fragments reporting that Kerberos rejected the clock difference
+var clockSkewMarkers = []string{
+	"Clock skew too great",
+	"KRB5KRB_AP_ERR_SKEW",
+	"KRB_AP_ERR_SKEW",
+	"NT_STATUS_TIME_DIFFERENCE_AT_DC",
+}
+
+// kerberosSkewTolerance is the default maximum clock difference Kerberos accepts
+const kerberosSkewTolerance = 5 * time.Minute
+
+// isClockSkew reports whether samba-tool stderr reports a Kerberos clock skew failure
+func isClockSkew(stderr string) bool {
+	for _, marker := range clockSkewMarkers {
+		if strings.Contains(stderr, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// clockSkew measures how far the local clock is ahead of the DC, using samba-tool time
+// The measurement is taken once per server and run; the runner is called directly so a
+// failing measurement is never retried or explained in turn
+func (c *SambaClient) clockSkew(server string) (time.Duration, error) {
+	fields, err := c.info.get("clockskew/"+server, func() (map[string]string, error) {
+		runner := c.runner
+		if runner == nil {
+			runner = execRunner{}
+		}
+		before := time.Now()
+		stdout, stderr, err := runner.run(c.Command, []string{"time", server}, c.authArgs())
+		if err != nil {
+			return nil, fmt.Errorf("samba-tool time: %v, stderr: %s", err, stderr)
+		}
+		// samba-tool prints the DC time with Python's time.ctime, in the local zone
+		remote, err := time.ParseInLocation(time.ANSIC, strings.TrimSpace(stdout), time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected samba-tool time output %q", strings.TrimSpace(stdout))
+		}
+		skew := before.Sub(remote).Round(time.Second)
+		return map[string]string{"skew": skew.String()}, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return time.ParseDuration(fields["skew"])
+}
+
+// explainClockSkew describes a clock skew failure against server, with the measured skew when available
+func (c *SambaClient) explainClockSkew(server string) string {
+	msg := "Kerberos rejected the request because the clocks of this host and the DC differ too much"
+	skew, err := c.clockSkew(server)
+	switch {
+	case err != nil:
+		msg += " (the difference could not be measured)"
+	case skew > 0:
+		msg += fmt.Sprintf(" (this host is %s ahead of %s, Kerberos allows %s)", skew, server, kerberosSkewTolerance)
+	default:
+		msg += fmt.Sprintf(" (this host is %s behind %s, Kerberos allows %s)", -skew, server, kerberosSkewTolerance)
+	}
+	return msg + "; sync this host's time with the domain (NTP against a DC, e.g. chronyd or w32time) and apply again"
+}
@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// credentialStatuses are samba-tool status codes that no retry or other resource can get past,
+// with a description of what is wrong with the account
+var credentialStatuses = []struct{ code, problem string }{
+	{"NT_STATUS_PASSWORD_MUST_CHANGE", "the password must be changed before the account can log on"},
+	{"NT_STATUS_PASSWORD_EXPIRED", "the password has expired"},
+	{"NT_STATUS_ACCOUNT_EXPIRED", "the account has expired"},
+	{"NT_STATUS_ACCOUNT_LOCKED_OUT", "the account is locked out"},
+	{"NT_STATUS_ACCOUNT_DISABLED", "the account is disabled"},
+	{"KDC_ERR_KEY_EXPIRED", "the password has expired"},
+	{"KDC_ERR_CLIENT_REVOKED", "the account is disabled, expired or locked out"},
+}
+
+// credentialStatus returns the account problem reported in samba-tool stderr, or ""
+func credentialStatus(stderr string) (code, problem string) {
+	for _, s := range credentialStatuses {
+		if strings.Contains(stderr, s.code) {
+			return s.code, s.problem
+		}
+	}
+	return "", ""
+}
+
+// credentialLatch remembers accounts samba-tool reported as unusable
+// Once an account is latched, further calls with it fail immediately instead of sending
+// more failed logons, which would fill the DC's logs and could lock the account out.
+// Clients cloned for per-resource credentials share the parent's latch.
+type credentialLatch struct {
+	mu     sync.Mutex
+	failed map[string]error
+}
+
+func newCredentialLatch() *credentialLatch {
+	return &credentialLatch{failed: make(map[string]error)}
+}
+
+// identity names the account a client authenticates as
+func (c *SambaClient) identity() string {
+	if c.Ccache != "" {
+		return "ccache " + c.Ccache
+	}
+	return c.Username
+}
+
+// check returns the latched error for the client's account, if any
+func (l *credentialLatch) check(c *SambaClient) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.failed[c.identity()]
+}
+
+// observe latches the client's account when stderr reports it unusable, returning the error to surface
+func (l *credentialLatch) observe(c *SambaClient, stderr string) error {
+	code, problem := credentialStatus(stderr)
+	if code == "" || l == nil {
+		return nil
+	}
+	err := fmt.Errorf("credentials for %s cannot be used: %s (%s); fix the account in Active Directory, "+
+		"or configure another account, then apply again. No further samba-tool calls are made with this account in this run: "+
+		"samba-tool error: stderr: %s", c.identity(), problem, code, strings.TrimSpace(stderr))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if first, ok := l.failed[c.identity()]; ok {
+		return first
+	}
+	l.failed[c.identity()] = err
+	return err
+}
@@ -0,0 +1,197 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// credentialsSchema returns the optional credentials block shared by resources and data sources
+func credentialsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Overrides the provider-level identity for this resource. Set either `username` and `password`, or `ccache`.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"username": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Username for samba-tool authentication (e.g., zoneadmin@EXAMPLE.COM).",
+				},
+				"password": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "Password for samba-tool authentication.",
+				},
+				"ccache": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Path to a Kerberos credential cache to authenticate with instead of a password.",
+				},
+			},
+		},
+	}
+}
+
+// zoneCredentialsSchema returns the provider-level identity for zone create and delete
+func zoneCredentialsSchema() *schema.Schema {
+	s := credentialsSchema()
+	s.Description = "Identity used only to create and delete zones, which needs Domain Admin rights, so the provider-level " +
+		"account can stay a low-privilege record writer. Set either `username` and `password`, or `ccache`. " +
+		"A zone with its own `profile` or `credentials` uses those instead."
+	return s
+}
+
+// profileSchema returns the optional profile argument shared by resources and data sources
+func profileSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Optional:    true,
+		Description: "Name of a provider `profile` block whose identity, realm and samba options this resource uses. A `credentials` block still takes precedence over the profile's identity.",
+	}
+}
+
+// profileBlockSchema returns the provider-level profile blocks
+func profileBlockSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		Description: "Named environments (e.g., one per forest) that resources select with `profile`, instead of one provider alias per environment.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"name": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "Profile name resources refer to (e.g., `prod-emea`).",
+				},
+				"username": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Username for samba-tool authentication in this environment.",
+				},
+				"password": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					Description: "Password for samba-tool authentication in this environment.",
+				},
+				"ccache": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Path to a Kerberos credential cache to authenticate with instead of a password.",
+				},
+				"realm": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Kerberos realm passed to samba-tool as `--realm` (e.g., `EMEA.EXAMPLE.COM`).",
+				},
+				"samba_options": {
+					Type:         schema.TypeMap,
+					Optional:     true,
+					Elem:         &schema.Schema{Type: schema.TypeString},
+					ValidateFunc: validateSambaOptions,
+					Description:  "smb.conf settings for this environment, merged over the provider-level `samba_options`.",
+				},
+			},
+		},
+	}
+}
+
+// expandProfiles builds a client per provider profile block, derived from the provider client
+func expandProfiles(base *SambaClient, raw []interface{}) (map[string]*SambaClient, error) {
+	profiles := make(map[string]*SambaClient, len(raw))
+	for _, v := range raw {
+		if v == nil {
+			continue
+		}
+		p := v.(map[string]interface{})
+		name := p["name"].(string)
+		if _, dup := profiles[name]; dup {
+			return nil, fmt.Errorf("profile %q is defined more than once", name)
+		}
+
+		c, err := withIdentity(base, p)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q: %w", name, err)
+		}
+
+		c.Realm = p["realm"].(string)
+		if options := p["samba_options"].(map[string]interface{}); len(options) > 0 {
+			merged := make(map[string]string, len(base.Options)+len(options))
+			for k, v := range base.Options {
+				merged[k] = v
+			}
+			for k, v := range options {
+				merged[k] = v.(string)
+			}
+			c.Options = merged
+		}
+		profiles[name] = c
+	}
+	return profiles, nil
+}
+
+// resourceGetter is the part of schema.ResourceData and schema.ResourceDiff clientFor needs
+type resourceGetter interface {
+	Get(key string) interface{}
+}
+
+// clientFor returns the samba client for a resource, honoring its profile and credentials block
+// The returned client records retries in the operation's retry log
+func clientFor(ctx context.Context, d resourceGetter, m interface{}) (*SambaClient, error) {
+	a := m.(*apiClient)
+	c := a.client
+	if name, _ := d.Get("profile").(string); name != "" {
+		profile, ok := a.profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("profile %q is not defined in the provider configuration", name)
+		}
+		c = profile
+	}
+	c = withResourceRetry(c, d).withRetryLog(retryLogFrom(ctx)).withSerialWarnings(serialWarningsFrom(ctx)).
+		withWindowCleared(windowClearedFrom(ctx))
+
+	blocks := d.Get("credentials").([]interface{})
+	if len(blocks) == 0 || blocks[0] == nil {
+		return c, nil
+	}
+
+	c, err := withIdentity(c, blocks[0].(map[string]interface{}))
+	if err != nil {
+		return nil, fmt.Errorf("credentials: %w", err)
+	}
+	return c, nil
+}
+
+// zoneClientFor is clientFor for zone create and delete, which need more rights than record writes
+// A resource without its own profile or credentials uses the provider zone_credentials when set
+func zoneClientFor(ctx context.Context, d resourceGetter, m interface{}) (*SambaClient, error) {
+	a := m.(*apiClient)
+	if a.zoneClient == nil || d.Get("profile").(string) != "" || len(d.Get("credentials").([]interface{})) > 0 {
+		return clientFor(ctx, d, m)
+	}
+	return withResourceRetry(a.zoneClient, d).withRetryLog(retryLogFrom(ctx)).withSerialWarnings(serialWarningsFrom(ctx)).
+		withWindowCleared(windowClearedFrom(ctx)), nil
+}
+
+// withIdentity returns a copy of c authenticating as a credentials block describes
+func withIdentity(c *SambaClient, creds map[string]interface{}) (*SambaClient, error) {
+	username := creds["username"].(string)
+	password := creds["password"].(string)
+	ccache := creds["ccache"].(string)
+
+	switch {
+	case ccache != "" && (username != "" || password != ""):
+		return nil, fmt.Errorf("set either ccache or username and password, not both")
+	case ccache != "":
+		return c.withCcache(ccache), nil
+	case username == "" || password == "":
+		return nil, fmt.Errorf("username and password are both required when ccache is not set")
+	default:
+		return c.withCredentials(username, password), nil
+	}
+}
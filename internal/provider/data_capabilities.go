@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceCapabilitiesExample is the example configuration shown in the data source documentation
+const dataSourceCapabilitiesExample = `
+data "sambadns_capabilities" "dc" {
+  server = "dc01.example.com"
+}
+
+output "manage_ttl" {
+  value = data.sambadns_capabilities.dc.ttl_write
+}
+`
+
+func dataSourceCapabilities() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Reports what the configured backend and DNS server support, so shared modules can branch on capabilities instead of DC versions.",
+			dataSourceCapabilitiesExample, ""),
+
+		ReadContext: wrapCRUD(dataSourceCapabilitiesRead),
+
+		Schema: map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			// Computed attributes
+			"backend": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Backend used to talk to the server (`samba-tool`, or `file` for the offline file backend).",
+			},
+			"server_name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Server name reported by the DNS server.",
+			},
+			"server_version": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Server version decoded from `dwVersion` as major.minor.build.",
+			},
+			"ds_available": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the server stores zones in Active Directory.",
+			},
+			"ttl_write": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether record TTLs can be written. samba-tool can only read them.",
+			},
+			"dnssec": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether DNSSEC signing can be managed.",
+			},
+			"zone_creation": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether zones can be created. Requires directory-backed zones.",
+			},
+			"wildcard_records": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether wildcard records can be created.",
+			},
+			"caa": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether CAA records can be managed.",
+			},
+			"notify": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether outbound DNS NOTIFY to secondaries can be configured.",
+			},
+			"record_flags_write": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether record ranks and flags such as DNS_RPC_FLAG_SUPPRESS_NOTIFY can be written. samba-tool can only read them.",
+			},
+			"forwarders_write": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether forwarders, their timeout and forwarders-only recursion can be written. samba-tool can only read them.",
+			},
+			"record_types": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Record types that can be managed.",
+			},
+			"profile":     profileSchema(),
+			"credentials": credentialsSchema(),
+		},
+	}
+}
+
+func dataSourceCapabilitiesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+
+	info, err := c.ServerInfo(server)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to query server info: %w", err))
+	}
+
+	dsAvailable := strings.EqualFold(info["fDsAvailable"], "TRUE")
+
+	d.SetId(server)
+	backend := "samba-tool"
+	if _, ok := c.runner.(*fileRunner); ok {
+		backend = "file"
+	}
+	state := newStateSetter(d)
+	state.set("backend", backend)
+	state.set("server_name", info["pszServerName"])
+	state.set("server_version", decodeServerVersion(info["dwVersion"]))
+	state.set("ds_available", dsAvailable)
+	state.set("ttl_write", false)
+	state.set("dnssec", false)
+	state.set("zone_creation", dsAvailable)
+	state.set("wildcard_records", true)
+	state.set("caa", false)
+	// The Samba internal DNS server neither sends NOTIFY nor serves zone transfers
+	state.set("notify", false)
+	state.set("record_flags_write", c.canWriteRecordFlags())
+	state.set("forwarders_write", c.canWriteForwarders())
+	state.set("record_types", supportedRecordTypes)
+
+	return state.diags
+}
+
+// decodeServerVersion converts dwVersion (e.g., 0xece0205) to "5.2.3790"
+// The low byte is the major version, the next byte the minor version and
+// the high word the build number
+func decodeServerVersion(raw string) string {
+	v, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(raw), "0x"), 16, 32)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d", v&0xff, (v>>8)&0xff, v>>16)
+}
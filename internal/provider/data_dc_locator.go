@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceDCLocatorExample is the example configuration shown in the data source documentation
+const dataSourceDCLocatorExample = `
+data "sambadns_dc_locator" "hq" {
+  realm    = "example.com"
+  site     = "HQ"
+  resolver = "dc01.example.com"
+}
+
+output "hq_dc" {
+  value = data.sambadns_dc_locator.hq.hostnames[0]
+}
+`
+
+func dataSourceDCLocator() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Discovers the domain controllers of a realm from DNS SRV records, optionally restricted to an AD site, "+
+			"for wiring into LDAP or Kerberos providers. Uses plain DNS, not samba-tool.",
+			dataSourceDCLocatorExample, ""),
+
+		ReadContext: wrapCRUD(dataSourceDCLocatorRead),
+
+		Schema: map[string]*schema.Schema{
+			"realm": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "AD DNS domain (e.g., example.com).",
+			},
+			"site": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "AD site name. Only DCs registered for the site are returned.",
+			},
+			"site_fallback": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "When the site has no DCs registered, fall back to every DC of the realm, as the Windows locator does.",
+			},
+			"resolver": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "DNS server to query (e.g., dc01.example.com). Defaults to the system resolver.",
+			},
+			"timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "10s",
+				ValidateFunc: validateDuration,
+				Description:  "Timeout for the whole lookup.",
+			},
+			// Computed attributes
+			"domain_controllers": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Discovered DCs in SRV priority and weight order.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"hostname": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "DC hostname.",
+						},
+						"port": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "LDAP port from the SRV record.",
+						},
+						"priority": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "SRV priority.",
+						},
+						"weight": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "SRV weight.",
+						},
+						"addresses": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "IPv4 and IPv6 addresses of the DC.",
+						},
+					},
+				},
+			},
+			"hostnames": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "DC hostnames, in the same order as `domain_controllers`.",
+			},
+			"site_matched": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the DCs came from the site-specific records. False when no site was given or the lookup fell back.",
+			},
+		},
+	}
+}
+
+// locatorNames returns the SRV names the DC locator queries, site-specific first
+func locatorNames(realm, site string) (siteName, realmName string) {
+	realm = strings.TrimSuffix(realm, ".")
+	realmName = "_ldap._tcp.dc._msdcs." + realm + "."
+	if site != "" {
+		siteName = "_ldap._tcp." + site + "._sites.dc._msdcs." + realm + "."
+	}
+	return siteName, realmName
+}
+
+func dataSourceDCLocatorRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	realm := d.Get("realm").(string)
+	site := d.Get("site").(string)
+	timeout, _ := time.ParseDuration(d.Get("timeout").(string))
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	r := net.DefaultResolver
+	if v := d.Get("resolver").(string); v != "" {
+		r = resolverFor(v)
+	}
+
+	siteName, realmName := locatorNames(realm, site)
+
+	var srvs []*net.SRV
+	siteMatched := false
+	if siteName != "" {
+		_, found, err := r.LookupSRV(ctx, "", "", siteName)
+		if err != nil && !isDNSNotFound(err) {
+			return diag.FromErr(fmt.Errorf("failed to look up %s: %w", siteName, err))
+		}
+		if len(found) > 0 {
+			srvs, siteMatched = found, true
+		} else if !d.Get("site_fallback").(bool) {
+			return diag.Errorf("no domain controllers registered for site %s in %s", site, realm)
+		}
+	}
+	if !siteMatched {
+		_, found, err := r.LookupSRV(ctx, "", "", realmName)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to look up %s: %w", realmName, err))
+		}
+		srvs = found
+	}
+
+	var dcs []interface{}
+	var hostnames []string
+	for _, srv := range srvs {
+		host := normalizeHostname(srv.Target)
+		addresses, err := r.LookupHost(ctx, host)
+		if err != nil && !isDNSNotFound(err) {
+			return diag.FromErr(fmt.Errorf("failed to resolve %s: %w", host, err))
+		}
+		sort.Strings(addresses)
+
+		dcs = append(dcs, map[string]interface{}{
+			"hostname":  host,
+			"port":      int(srv.Port),
+			"priority":  int(srv.Priority),
+			"weight":    int(srv.Weight),
+			"addresses": addresses,
+		})
+		hostnames = append(hostnames, host)
+	}
+
+	if site != "" {
+		d.SetId(fmt.Sprintf("%s/%s", realm, site))
+	} else {
+		d.SetId(realm)
+	}
+	state := newStateSetter(d)
+	state.set("domain_controllers", dcs)
+	state.set("hostnames", hostnames)
+	state.set("site_matched", siteMatched)
+
+	return state.diags
+}
+
+// isDNSNotFound reports whether a lookup failed because the name has no records
+func isDNSNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
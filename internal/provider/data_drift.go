@@ -0,0 +1,267 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// driftRecordSchema describes a record in the drift report
+func driftRecordSchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Record name.",
+			},
+			"type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Record type.",
+			},
+			"value": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Record value.",
+			},
+		},
+	}
+}
+
+// dataSourceDriftExample is the example configuration shown in the data source documentation
+const dataSourceDriftExample = `
+data "sambadns_drift" "audit" {
+  server = "dc01.example.com"
+  zone   = "example.com"
+
+  expected {
+    name  = "web"
+    type  = "A"
+    value = "10.0.0.11"
+  }
+  expected {
+    name  = "www"
+    type  = "CNAME"
+    value = "web.example.com"
+  }
+}
+
+output "dns_in_sync" {
+  value = data.sambadns_drift.audit.in_sync
+}
+`
+
+func dataSourceDrift() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Compares an expected set of records against the live zone and reports additions, removals and "+
+			"changes without modifying anything. Intended for scheduled audit pipelines.",
+			dataSourceDriftExample, ""),
+
+		ReadContext: wrapCRUD(dataSourceDriftRead),
+
+		Schema: map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS zone name (e.g., example.com).",
+			},
+			"expected": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Records the zone should contain.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Record name.",
+						},
+						"type": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(supportedRecordTypes, true),
+							Description:  "Record type.",
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Required:    true,
+							Description: "Record value, in the same format as `sambadns_record`.",
+						},
+					},
+				},
+			},
+			"types": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.StringInSlice(supportedRecordTypes, true)},
+				Description: "Record types to compare. Defaults to the types used in `expected`. Live records of other types are ignored.",
+			},
+			// Computed attributes
+			"additions": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        driftRecordSchema(),
+				Description: "Expected records missing from the zone.",
+			},
+			"removals": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        driftRecordSchema(),
+				Description: "Live records that are not expected.",
+			},
+			"changes": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Names whose record of a type holds a different value than expected.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Record name.",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Record type.",
+						},
+						"expected": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Expected value.",
+						},
+						"actual": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Live value.",
+						},
+					},
+				},
+			},
+			"in_sync": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether no additions, removals or changes were found.",
+			},
+			"profile":     profileSchema(),
+			"credentials": credentialsSchema(),
+		},
+	}
+}
+
+func dataSourceDriftRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+
+	expected := make(map[string][]string)
+	types := make(map[string]bool)
+	for _, raw := range d.Get("expected").([]interface{}) {
+		e := raw.(map[string]interface{})
+		recordType := strings.ToUpper(e["type"].(string))
+		key := e["name"].(string) + "/" + recordType
+		expected[key] = append(expected[key], e["value"].(string))
+		types[recordType] = true
+	}
+	if v := d.Get("types").(*schema.Set); v.Len() > 0 {
+		types = make(map[string]bool)
+		for _, t := range setToStrings(v.List()) {
+			types[strings.ToUpper(t)] = true
+		}
+	}
+
+	// Only the compared types are kept while the zone streams in
+	records, _, err := c.ListZoneRecordsFiltered(server, zone, zoneFilter{Types: types})
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to enumerate zone: %w", err))
+	}
+
+	live := make(map[string][]string)
+	for _, r := range records {
+		if types[r.Type] {
+			key := r.Name + "/" + r.Type
+			live[key] = append(live[key], r.Value)
+		}
+	}
+
+	var additions, removals, changes []interface{}
+	keys := make(map[string]bool)
+	for k := range expected {
+		keys[k] = true
+	}
+	for k := range live {
+		keys[k] = true
+	}
+
+	for _, key := range sortedKeys(keys) {
+		name, recordType, _ := strings.Cut(key, "/")
+		if !types[recordType] {
+			continue
+		}
+		missing, extra := unmatchedValues(recordType, expected[key], live[key])
+
+		// Pair leftovers up as in-place changes, the rest are pure additions or removals
+		for len(missing) > 0 && len(extra) > 0 {
+			changes = append(changes, map[string]interface{}{
+				"name":     name,
+				"type":     recordType,
+				"expected": missing[0],
+				"actual":   extra[0],
+			})
+			missing, extra = missing[1:], extra[1:]
+		}
+		for _, v := range missing {
+			additions = append(additions, map[string]interface{}{"name": name, "type": recordType, "value": v})
+		}
+		for _, v := range extra {
+			removals = append(removals, map[string]interface{}{"name": name, "type": recordType, "value": v})
+		}
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", server, zone))
+	state := newStateSetter(d)
+	state.set("additions", additions)
+	state.set("removals", removals)
+	state.set("changes", changes)
+	state.set("in_sync", len(additions)+len(removals)+len(changes) == 0)
+
+	return state.diags
+}
+
+// unmatchedValues drops values present on both sides (after normalization) and returns the rest
+func unmatchedValues(recordType string, want, have []string) (missing, extra []string) {
+	used := make([]bool, len(have))
+	for _, w := range want {
+		matched := false
+		for i, h := range have {
+			if !used[i] && recordValuesEqual(recordType, w, h) {
+				used[i] = true
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			missing = append(missing, w)
+		}
+	}
+	for i, h := range have {
+		if !used[i] {
+			extra = append(extra, h)
+		}
+	}
+	return missing, extra
+}
@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceDuplicateReportExample is the example configuration shown in the data source documentation
+const dataSourceDuplicateReportExample = `
+data "sambadns_duplicate_report" "audit" {
+  server       = "dc01.example.com"
+  zone         = "example.com"
+  ignore_names = ["@", "DomainDnsZones", "ForestDnsZones"]
+}
+
+output "dns_issues" {
+  value = data.sambadns_duplicate_report.audit.issue_count
+}
+`
+
+func dataSourceDuplicateReport() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Enumerates a zone and reports conflicting or suspicious entries: CNAMEs coexisting with other types, "+
+			"addresses shared by several names, and names with more than one PTR.",
+			dataSourceDuplicateReportExample, ""),
+
+		ReadContext: wrapCRUD(dataSourceDuplicateReportRead),
+
+		Schema: map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS zone name (e.g., example.com).",
+			},
+			"ignore_names": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Names to leave out of the report, e.g. `@`, `DomainDnsZones` and `ForestDnsZones`, which share the DC addresses by design in AD zones.",
+			},
+			// Computed attributes
+			"cname_conflicts": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Names holding a CNAME alongside records of other types.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Record name.",
+						},
+						"types": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Record types present at the name.",
+						},
+					},
+				},
+			},
+			"duplicate_addresses": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "A/AAAA addresses published under more than one name.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"address": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "IP address.",
+						},
+						"names": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Names pointing at the address.",
+						},
+					},
+				},
+			},
+			"multiple_ptrs": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Reverse names holding more than one PTR record.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Record name.",
+						},
+						"values": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "PTR targets.",
+						},
+					},
+				},
+			},
+			"issue_count": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Total number of reported entries.",
+			},
+			"profile":     profileSchema(),
+			"credentials": credentialsSchema(),
+		},
+	}
+}
+
+func dataSourceDuplicateReportRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+
+	ignore := make(map[string]bool)
+	for _, n := range d.Get("ignore_names").(*schema.Set).List() {
+		ignore[n.(string)] = true
+	}
+
+	records, err := c.ListZoneRecords(server, zone)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to enumerate zone: %w", err))
+	}
+
+	typesByName := make(map[string]map[string]bool)
+	namesByAddress := make(map[string]map[string]bool)
+	ptrsByName := make(map[string][]string)
+
+	for _, r := range records {
+		if ignore[r.Name] {
+			continue
+		}
+		if typesByName[r.Name] == nil {
+			typesByName[r.Name] = make(map[string]bool)
+		}
+		typesByName[r.Name][r.Type] = true
+
+		switch r.Type {
+		case "A", "AAAA":
+			address := normalizeIPv6(r.Value)
+			if namesByAddress[address] == nil {
+				namesByAddress[address] = make(map[string]bool)
+			}
+			namesByAddress[address][r.Name] = true
+		case "PTR":
+			ptrsByName[r.Name] = append(ptrsByName[r.Name], r.Value)
+		}
+	}
+
+	var cnameConflicts []interface{}
+	for _, name := range sortedKeys(typesByName) {
+		types := typesByName[name]
+		if types["CNAME"] && len(types) > 1 {
+			cnameConflicts = append(cnameConflicts, map[string]interface{}{
+				"name":  name,
+				"types": sortedKeys(types),
+			})
+		}
+	}
+
+	var duplicateAddresses []interface{}
+	for _, address := range sortedKeys(namesByAddress) {
+		names := namesByAddress[address]
+		if len(names) > 1 {
+			duplicateAddresses = append(duplicateAddresses, map[string]interface{}{
+				"address": address,
+				"names":   sortedKeys(names),
+			})
+		}
+	}
+
+	var multiplePTRs []interface{}
+	for _, name := range sortedKeys(ptrsByName) {
+		values := ptrsByName[name]
+		if len(values) > 1 {
+			sort.Strings(values)
+			multiplePTRs = append(multiplePTRs, map[string]interface{}{
+				"name":   name,
+				"values": values,
+			})
+		}
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", server, zone))
+	state := newStateSetter(d)
+	state.set("cname_conflicts", cnameConflicts)
+	state.set("duplicate_addresses", duplicateAddresses)
+	state.set("multiple_ptrs", multiplePTRs)
+	state.set("issue_count", len(cnameConflicts)+len(duplicateAddresses)+len(multiplePTRs))
+
+	return state.diags
+}
+
+// sortedKeys returns the keys of a string-keyed map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
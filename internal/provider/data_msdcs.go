@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// srvEntrySchema describes an SRV record in a locator listing
+func srvEntrySchema() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			"target": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Host offering the service.",
+			},
+			"port": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Service port.",
+			},
+			"priority": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "SRV priority.",
+			},
+			"weight": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "SRV weight.",
+			},
+		},
+	}
+}
+
+// dataSourceMSDCSExample is the example configuration shown in the data source documentation
+const dataSourceMSDCSExample = `
+data "sambadns_msdcs" "forest" {
+  server = "dc01.example.com"
+  forest = "example.com"
+}
+
+output "gc_hosts" {
+  value = [for gc in data.sambadns_msdcs.forest.global_catalogs : gc.target]
+}
+`
+
+func dataSourceMSDCS() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Reads the AD locator records under `_msdcs`: domain controllers, global catalogs, the PDC emulator "+
+			"and the DSA GUID aliases. Read-only, so modules can discover DCs from DNS without managing the records.",
+			dataSourceMSDCSExample, ""),
+
+		ReadContext: wrapCRUD(dataSourceMSDCSRead),
+
+		Schema: map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"forest": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Forest root domain (e.g., example.com). The records are read from the `_msdcs.<forest>` zone.",
+			},
+			// Computed attributes
+			"domain_controllers": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        srvEntrySchema(),
+				Description: "Entries of `_ldap._tcp.dc._msdcs`.",
+			},
+			"global_catalogs": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        srvEntrySchema(),
+				Description: "Entries of `_ldap._tcp.gc._msdcs`.",
+			},
+			"pdc_emulators": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        srvEntrySchema(),
+				Description: "Entries of `_ldap._tcp.pdc._msdcs`; one per domain in the forest.",
+			},
+			"dsa_aliases": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "DSA GUID CNAMEs used for replication, keyed by GUID, valued by DC hostname.",
+			},
+			"profile":     profileSchema(),
+			"credentials": credentialsSchema(),
+		},
+	}
+}
+
+// srvEntry is a parsed SRV value
+type srvEntry struct {
+	Target   string
+	Port     int
+	Priority int
+	Weight   int
+}
+
+// parseSRVValue parses an SRV value in "target port priority weight" format
+func parseSRVValue(value string) (srvEntry, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 4 {
+		return srvEntry{}, fmt.Errorf("unexpected SRV value %q (expected target port priority weight)", value)
+	}
+	numbers := make([]int, 3)
+	for i, f := range fields[1:] {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return srvEntry{}, fmt.Errorf("unexpected SRV value %q: %w", value, err)
+		}
+		numbers[i] = n
+	}
+	return srvEntry{
+		Target:   normalizeHostname(fields[0]),
+		Port:     numbers[0],
+		Priority: numbers[1],
+		Weight:   numbers[2],
+	}, nil
+}
+
+func (e srvEntry) flatten() map[string]interface{} {
+	return map[string]interface{}{
+		"target":   e.Target,
+		"port":     e.Port,
+		"priority": e.Priority,
+		"weight":   e.Weight,
+	}
+}
+
+// isGUIDLabel reports whether a label looks like a DSA GUID (8-4-4-4-12 hex digits)
+func isGUIDLabel(label string) bool {
+	parts := strings.Split(label, "-")
+	if len(parts) != 5 {
+		return false
+	}
+	for i, n := range []int{8, 4, 4, 4, 12} {
+		if len(parts[i]) != n {
+			return false
+		}
+		if _, err := strconv.ParseUint(parts[i], 16, 64); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func dataSourceMSDCSRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := "_msdcs." + strings.TrimSuffix(d.Get("forest").(string), ".")
+
+	records, err := c.ListZoneRecords(server, zone)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to enumerate %s: %w", zone, err))
+	}
+
+	services := map[string][]interface{}{}
+	aliases := map[string]interface{}{}
+	for _, r := range records {
+		switch {
+		case r.Type == "SRV":
+			name := strings.ToLower(r.Name)
+			if name != "_ldap._tcp.dc" && name != "_ldap._tcp.gc" && name != "_ldap._tcp.pdc" {
+				continue
+			}
+			entry, err := parseSRVValue(r.Value)
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			services[name] = append(services[name], entry.flatten())
+		case r.Type == "CNAME" && isGUIDLabel(r.Name):
+			aliases[strings.ToLower(r.Name)] = normalizeHostname(r.Value)
+		}
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", server, zone))
+	state := newStateSetter(d)
+	state.set("domain_controllers", services["_ldap._tcp.dc"])
+	state.set("global_catalogs", services["_ldap._tcp.gc"])
+	state.set("pdc_emulators", services["_ldap._tcp.pdc"])
+	state.set("dsa_aliases", aliases)
+
+	return state.diags
+}
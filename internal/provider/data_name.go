@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceNameExample is the example configuration shown in the data source documentation
+const dataSourceNameExample = `
+data "sambadns_name" "web" {
+  server = "dc01.example.com"
+  zone   = "example.com"
+  name   = "web"
+}
+
+output "web_types" {
+  value = data.sambadns_name.web.types
+}
+`
+
+func dataSourceName() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Discovers every record stored at a name, whatever its type (samba-tool type `ALL`).",
+			dataSourceNameExample, ""),
+
+		ReadContext: wrapCRUD(dataSourceNameRead),
+
+		Schema: map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS zone name (e.g., example.com).",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Record name to look up.",
+			},
+			// Computed attributes
+			"found": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether any record exists at the name.",
+			},
+			"types": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Sorted list of record types present at the name.",
+			},
+			"values": {
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Map of record type to its values. Multiple values of one type are separated by newlines; use `split(\"\\n\", ...)` to get a list.",
+			},
+			"records": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Every record at the name, in server order.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Record type.",
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Record value.",
+						},
+						"ttl": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Time to live in seconds.",
+						},
+					},
+				},
+			},
+			"profile":     profileSchema(),
+			"credentials": credentialsSchema(),
+		},
+	}
+}
+
+func dataSourceNameRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+	name := d.Get("name").(string)
+
+	records, err := c.QueryName(server, zone, name)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to query name: %w", err))
+	}
+
+	grouped := make(map[string][]string)
+	var list []interface{}
+	for _, r := range records {
+		grouped[r.Type] = append(grouped[r.Type], r.Value)
+		list = append(list, map[string]interface{}{
+			"type":  r.Type,
+			"value": r.Value,
+			"ttl":   r.TTL,
+		})
+	}
+
+	types := make([]string, 0, len(grouped))
+	values := make(map[string]interface{}, len(grouped))
+	for t, v := range grouped {
+		types = append(types, t)
+		values[t] = strings.Join(v, "\n")
+	}
+	sort.Strings(types)
+
+	d.SetId(buildNameID(server, zone, name))
+	state := newStateSetter(d)
+	state.set("found", len(records) > 0)
+	state.set("types", types)
+	state.set("values", values)
+	state.set("records", list)
+
+	return state.diags
+}
@@ -0,0 +1,106 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceNameAvailableExample is the example configuration shown in the data source documentation
+const dataSourceNameAvailableExample = `
+data "sambadns_name_available" "new_app" {
+  server         = "dc01.example.com"
+  zone           = "example.com"
+  name           = "new-app"
+  fail_if_exists = true
+}
+`
+
+func dataSourceNameAvailable() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Asserts that a name does not exist with any record type, so provisioning modules can fail early "+
+			"instead of clobbering an existing service's record.",
+			dataSourceNameAvailableExample, ""),
+
+		ReadContext: wrapCRUD(dataSourceNameAvailableRead),
+
+		Schema: map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS zone name (e.g., example.com).",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Record name that should be free.",
+			},
+			"fail_if_exists": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Return an error when the name exists, instead of `available = false`.",
+			},
+			// Computed attributes
+			"available": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether no record of any type exists at the name.",
+			},
+			"existing_types": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Record types found at the name.",
+			},
+			"profile":     profileSchema(),
+			"credentials": credentialsSchema(),
+		},
+	}
+}
+
+func dataSourceNameAvailableRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+	name := d.Get("name").(string)
+
+	records, err := c.QueryName(server, zone, name)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to query name: %w", err))
+	}
+
+	seen := make(map[string]bool)
+	var types []string
+	for _, r := range records {
+		if !seen[r.Type] {
+			seen[r.Type] = true
+			types = append(types, r.Type)
+		}
+	}
+	sort.Strings(types)
+
+	if len(types) > 0 && d.Get("fail_if_exists").(bool) {
+		return diag.Errorf("name %s in zone %s is already in use (%s)", name, zone, strings.Join(types, ", "))
+	}
+
+	d.SetId(buildNameID(server, zone, name))
+	state := newStateSetter(d)
+	state.set("available", len(types) == 0)
+	state.set("existing_types", types)
+
+	return state.diags
+}
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourcePreflightExample is the example configuration shown in the data source documentation
+const dataSourcePreflightExample = `
+data "sambadns_preflight" "corp" {
+  server = "dc01.example.com"
+  zone   = "example.com"
+}
+
+resource "sambadns_record" "web" {
+  server = "dc01.example.com"
+  zone   = "example.com"
+  name   = "web"
+  type   = "A"
+  value  = "10.0.0.5"
+
+  lifecycle {
+    precondition {
+      condition     = data.sambadns_preflight.corp.ready
+      error_message = "Cannot manage example.com: ${data.sambadns_preflight.corp.message}"
+    }
+  }
+}
+`
+
+func dataSourcePreflight() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Checks whether the configured account can actually manage a zone, by reading the zone and "+
+			"creating and deleting a probe TXT record, before a large apply fails halfway through.",
+			dataSourcePreflightExample, ""),
+
+		ReadContext: wrapCRUD(dataSourcePreflightRead),
+
+		Schema: map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS zone name (e.g., example.com).",
+			},
+			"probe_name": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Default:     "_sambadns-preflight",
+				Description: "Name of the probe record. Use a name nothing else relies on.",
+			},
+			"write_probe": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Create and delete the probe record. Set to `false` to only check read access.",
+			},
+			// Computed attributes
+			"can_read": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the zone information could be read.",
+			},
+			"can_write": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the probe record could be created.",
+			},
+			"can_delete": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the probe record could be deleted.",
+			},
+			"ready": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether every performed check passed.",
+			},
+			"message": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Error from the first failed check, empty when ready.",
+			},
+			"profile":     profileSchema(),
+			"credentials": credentialsSchema(),
+		},
+	}
+}
+
+func dataSourcePreflightRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+
+	var canRead, canWrite, canDelete bool
+	message := ""
+
+	if _, err := c.ZoneInfo(server, zone); err != nil {
+		message = fmt.Sprintf("read zone info: %s", err)
+	} else {
+		canRead = true
+	}
+
+	ready := canRead
+	if canRead && d.Get("write_probe").(bool) {
+		probe := DNSRecord{
+			Server: server,
+			Zone:   zone,
+			Name:   d.Get("probe_name").(string),
+			Type:   "TXT",
+			Value:  fmt.Sprintf("sambadns-preflight-%d", time.Now().Unix()),
+		}
+
+		if err := c.CreateRecord(probe); err != nil {
+			message = fmt.Sprintf("create probe record: %s", err)
+		} else {
+			canWrite = true
+			if err := c.DeleteRecord(probe); err != nil {
+				message = fmt.Sprintf("delete probe record %s (remove it by hand): %s", probe.Name, err)
+			} else {
+				canDelete = true
+			}
+		}
+		ready = canWrite && canDelete
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", server, zone))
+	state := newStateSetter(d)
+	state.set("can_read", canRead)
+	state.set("can_write", canWrite)
+	state.set("can_delete", canDelete)
+	state.set("ready", ready)
+	state.set("message", message)
+
+	return state.diags
+}
@@ -4,17 +4,37 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+// dataRecordTypes are the types the record data source accepts; ALL reads every type at the name
+var dataRecordTypes = append(append([]string{}, supportedRecordTypes...), "ALL")
+
+// dataSourceRecordExample is the example configuration shown in the data source documentation
+const dataSourceRecordExample = `
+data "sambadns_record" "existing" {
+  server        = "dc01.example.com"
+  zone          = "example.com"
+  name          = "web"
+  type          = "A"
+  allow_missing = true
+}
+
+output "web_ip" {
+  value = data.sambadns_record.existing.found ? data.sambadns_record.existing.value : null
+}
+`
+
 func dataSourceRecord() *schema.Resource {
 	return &schema.Resource{
-		Description: "Reads an existing DNS record via samba-tool.",
+		Description: docDescription("Reads an existing DNS record via samba-tool.",
+			dataSourceRecordExample, ""),
 
-		ReadContext: dataSourceRecordRead,
+		ReadContext: wrapCRUD(dataSourceRecordRead),
 
 		Schema: map[string]*schema.Schema{
 			"dns_server": {
@@ -33,49 +53,256 @@ func dataSourceRecord() *schema.Resource {
 				Description: "Record name to look up.",
 			},
 			"type": {
-				Type:     schema.TypeString,
-				Required: true,
-				ValidateFunc: validation.StringInSlice([]string{
-					"A", "AAAA", "CNAME", "TXT", "MX", "PTR", "SRV", "NS",
-				}, true),
-				StateFunc:   func(v interface{}) string { return strings.ToUpper(v.(string)) },
-				Description: "Record type (A, AAAA, CNAME, TXT, MX, PTR, SRV, NS).",
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(dataRecordTypes, true),
+				StateFunc:    func(v interface{}) string { return strings.ToUpper(v.(string)) },
+				Description:  "Record type (A, AAAA, CNAME, TXT, MX, PTR, SRV, NS), or `ALL` to read every record at the name into `records`.",
+			},
+			"allow_missing": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Return `found = false` with a null value instead of an error when the record does not exist.",
+			},
+			"include_raw": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Populate `raw_output` with the unparsed samba-tool output, for debugging values that look wrong.",
 			},
 			// Computed attributes
+			"found": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the record exists. With type `ALL`, whether any record exists at the name.",
+			},
 			"value": {
 				Type:        schema.TypeString,
 				Computed:    true,
-				Description: "The record value.",
+				Description: "The record value. Empty with type `ALL`.",
+			},
+			"canonical_value": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The stored value in the normalized form values are compared in.",
 			},
 			"ttl": {
 				Type:        schema.TypeInt,
 				Computed:    true,
 				Description: "Time to live in seconds.",
 			},
+			"dynamic": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the record was registered dynamically, i.e. carries an aging timestamp.",
+			},
+			"timestamp": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "RFC 3339 UTC time a dynamic record was last registered or refreshed, to the hour. Empty for static records.",
+			},
+			"age_days": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Whole days since a dynamic record was last refreshed. 0 for static records; check `dynamic`.",
+			},
+			"records": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The records read, in server order: every record at the name with type `ALL`, otherwise the one record.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Record type.",
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Record value.",
+						},
+						"canonical_value": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Record value in normalized form.",
+						},
+						"ttl": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Time to live in seconds.",
+						},
+						"dynamic": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the record was registered dynamically.",
+						},
+						"timestamp": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "RFC 3339 UTC time a dynamic record was last registered or refreshed, to the hour; empty for static records.",
+						},
+						"age_days": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Whole days since a dynamic record was last refreshed. 0 for static records.",
+						},
+					},
+				},
+			},
+			"children": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of child nodes below the name, from the `Children=` field of samba-tool's output. Non-zero when the name has sub-names, e.g. a delegated subdomain, which a CNAME at the name would break.",
+			},
+			"raw_output": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Unparsed samba-tool query output. Only set when `include_raw` is true.",
+			},
+			"profile":     profileSchema(),
+			"credentials": credentialsSchema(),
 		},
 	}
 }
 
 func dataSourceRecordRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*apiClient).client
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	server := d.Get("dns_server").(string)
 	zone := d.Get("zone").(string)
 	name := d.Get("name").(string)
 	recordType := strings.ToUpper(d.Get("type").(string))
 
-	record, err := c.QueryRecord(server, zone, name, recordType)
+	if recordType == "ALL" {
+		return dataSourceRecordReadAll(d, c, server, zone, name)
+	}
+
+	record, raw, err := c.QueryRecordRaw(server, zone, name, recordType)
 	if err != nil {
+		if raw != "" && d.Get("include_raw").(bool) {
+			return diag.FromErr(fmt.Errorf("failed to query record: %w\nraw output:\n%s", err, raw))
+		}
 		return diag.FromErr(fmt.Errorf("failed to query record: %w", err))
 	}
 
+	state := newStateSetter(d)
+	if d.Get("include_raw").(bool) {
+		state.set("raw_output", raw)
+	} else {
+		state.set("raw_output", "")
+	}
+
+	children, ok := nodeChildren(raw)
+	if !ok {
+		// No record of the type: the name may still exist with other records or only children
+		if children, err = queryChildren(c, server, zone, name); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
 	if record == nil {
-		return diag.Errorf("record not found: %s %s in zone %s", name, recordType, zone)
+		if !d.Get("allow_missing").(bool) {
+			return diag.Errorf("record not found: %s %s in zone %s", name, recordType, zone)
+		}
+		d.SetId(buildID(server, zone, name, recordType))
+		state.set("found", false)
+		state.set("children", children)
+		setRecordAge(state, DNSRecord{}, time.Now())
+		state.set("records", nil)
+		return state.diags
 	}
 
 	d.SetId(buildID(server, zone, name, recordType))
-	d.Set("value", record.Value)
-	d.Set("ttl", record.TTL)
+	state.set("found", true)
+	state.set("children", children)
+	state.set("value", record.Value)
+	state.set("canonical_value", canonicalValue(record.Type, record.Value))
+	state.set("ttl", record.TTL)
+	setRecordAge(state, *record, time.Now())
+	state.set("records", typedRecords([]DNSRecord{*record}))
+
+	return state.diags
+}
+
+// dataSourceRecordReadAll reads every record at a name for type ALL
+func dataSourceRecordReadAll(d *schema.ResourceData, c *SambaClient, server, zone, name string) diag.Diagnostics {
+	records, raw, err := c.QueryNameRaw(server, zone, name)
+	if err != nil {
+		if raw != "" && d.Get("include_raw").(bool) {
+			return diag.FromErr(fmt.Errorf("failed to query name: %w\nraw output:\n%s", err, raw))
+		}
+		return diag.FromErr(fmt.Errorf("failed to query name: %w", err))
+	}
+
+	state := newStateSetter(d)
+	if d.Get("include_raw").(bool) {
+		state.set("raw_output", raw)
+	} else {
+		state.set("raw_output", "")
+	}
+
+	if len(records) == 0 && !d.Get("allow_missing").(bool) {
+		return diag.Errorf("no records found at %s in zone %s", name, zone)
+	}
 
-	return nil
+	children, _ := nodeChildren(raw)
+
+	d.SetId(buildID(server, zone, name, "ALL"))
+	state.set("found", len(records) > 0)
+	state.set("children", children)
+	state.set("value", "")
+	state.set("canonical_value", "")
+	state.set("ttl", 0)
+	setRecordAge(state, DNSRecord{}, time.Now())
+	state.set("records", typedRecords(records))
+
+	return state.diags
+}
+
+// queryChildren returns the child node count of a name, 0 when the name does not exist
+func queryChildren(c *SambaClient, server, zone, name string) (int, error) {
+	_, raw, err := c.QueryNameRaw(server, zone, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query child nodes of %s: %w", name, err)
+	}
+	children, _ := nodeChildren(raw)
+	return children, nil
+}
+
+// typedRecords converts records to the records attribute of the record data source
+func typedRecords(records []DNSRecord) []interface{} {
+	now := time.Now()
+	list := make([]interface{}, 0, len(records))
+	for _, r := range records {
+		list = append(list, map[string]interface{}{
+			"type":            r.Type,
+			"value":           r.Value,
+			"canonical_value": canonicalValue(r.Type, r.Value),
+			"ttl":             r.TTL,
+			"dynamic":         !r.Timestamp.IsZero(),
+			"timestamp":       recordTimestamp(r),
+			"age_days":        recordAgeDays(r, now),
+		})
+	}
+	return list
+}
+
+// setRecordAge sets the dynamic, timestamp and age_days attributes of a record
+func setRecordAge(state *stateSetter, r DNSRecord, now time.Time) {
+	state.set("dynamic", !r.Timestamp.IsZero())
+	state.set("timestamp", recordTimestamp(r))
+	state.set("age_days", recordAgeDays(r, now))
+}
+
+// recordTimestamp formats the timestamp of a dynamic record for state, "" for static records
+func recordTimestamp(r DNSRecord) string {
+	if r.Timestamp.IsZero() {
+		return ""
+	}
+	return r.Timestamp.UTC().Format(time.RFC3339)
 }
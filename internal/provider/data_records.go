@@ -0,0 +1,173 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// dataSourceRecordsExample is the example configuration shown in the data source documentation
+const dataSourceRecordsExample = `
+data "sambadns_records" "web" {
+  server      = "dc01.example.com"
+  zone        = "example.com"
+  name_prefix = "web"
+  types       = ["A", "AAAA"]
+  limit       = 100
+}
+
+output "web_hosts" {
+  value = { for r in data.sambadns_records.web.records : r.name => r.value... }
+}
+`
+
+func dataSourceRecords() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Lists the records of a zone, optionally filtered by name prefix and type and paged with `limit` and `offset`. "+
+			"Filters are applied while samba-tool output streams in, and enumeration stops once the page is full.",
+			dataSourceRecordsExample, ""),
+
+		ReadContext: wrapCRUD(dataSourceRecordsRead),
+
+		Schema: map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS zone name (e.g., example.com).",
+			},
+			"name_prefix": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Only list records whose name starts with this prefix, compared case-insensitively (e.g., `web`).",
+			},
+			"types": {
+				Type:        schema.TypeSet,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Only list records of these types (e.g., `A`, `CNAME`). Defaults to every type.",
+			},
+			"limit": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(1),
+				Description:  "Maximum number of records to return. Unlimited when unset.",
+			},
+			"offset": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntAtLeast(0),
+				Description:  "Number of matching records to skip, in enumeration order.",
+			},
+			// Computed attributes
+			"records": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Matching records.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Record name.",
+						},
+						"type": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Record type.",
+						},
+						"value": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Record value.",
+						},
+						"ttl": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Time to live in seconds.",
+						},
+						"dynamic": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the record was registered dynamically.",
+						},
+						"timestamp": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "RFC 3339 UTC time a dynamic record was last registered or refreshed, to the hour; empty for static records.",
+						},
+						"age_days": {
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Whole days since a dynamic record was last refreshed. 0 for static records.",
+						},
+					},
+				},
+			},
+			"more": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether matching records beyond `limit` exist; request the next page with `offset` increased by `limit`.",
+			},
+			"profile":     profileSchema(),
+			"credentials": credentialsSchema(),
+		},
+	}
+}
+
+func dataSourceRecordsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+
+	filter := zoneFilter{
+		NamePrefix: d.Get("name_prefix").(string),
+		Offset:     d.Get("offset").(int),
+		Limit:      d.Get("limit").(int),
+	}
+	if v := d.Get("types").(*schema.Set); v.Len() > 0 {
+		filter.Types = make(map[string]bool)
+		for _, t := range setToStrings(v.List()) {
+			filter.Types[strings.ToUpper(t)] = true
+		}
+	}
+
+	records, more, err := c.ListZoneRecordsFiltered(server, zone, filter)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to enumerate zone: %w", err))
+	}
+
+	now := time.Now()
+	list := make([]interface{}, 0, len(records))
+	for _, r := range records {
+		list = append(list, map[string]interface{}{
+			"name":      r.Name,
+			"type":      r.Type,
+			"value":     r.Value,
+			"ttl":       r.TTL,
+			"dynamic":   !r.Timestamp.IsZero(),
+			"timestamp": recordTimestamp(r),
+			"age_days":  recordAgeDays(r, now),
+		})
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", server, zone))
+	state := newStateSetter(d)
+	state.set("records", list)
+	state.set("more", more)
+
+	return state.diags
+}
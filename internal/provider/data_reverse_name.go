@@ -0,0 +1,146 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// dataSourceReverseNameExample is the example configuration shown in the data source documentation
+const dataSourceReverseNameExample = `
+data "sambadns_reverse_name" "web6" {
+  address       = "2001:db8:0:1::5"
+  prefix_length = 56
+}
+
+resource "sambadns_record" "web6_ptr" {
+  server = "dc01.example.com"
+  fqdn   = data.sambadns_reverse_name.web6.fqdn
+  type   = "PTR"
+  value  = "web.example.com"
+}
+`
+
+func dataSourceReverseName() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Computes the in-addr.arpa or ip6.arpa (nibble format) name of an address, and the reverse zone "+
+			"and relative name for a prefix length. Makes no samba-tool calls.",
+			dataSourceReverseNameExample, ""),
+
+		ReadContext: dataSourceReverseNameRead,
+
+		Schema: map[string]*schema.Schema{
+			"address": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsIPAddress,
+				Description:  "IPv4 or IPv6 address (e.g., `10.0.0.5` or `2001:db8:0:1::5`).",
+			},
+			"prefix_length": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IntBetween(1, 128),
+				Description: "Prefix length of the reverse zone: a multiple of 8 for IPv4 (default 24) and of 4 for IPv6 (default 64, " +
+					"e.g. 56 for a /56 delegation). Reverse zones can only be cut at label boundaries.",
+			},
+			// Computed attributes
+			"fqdn": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Full reverse name of the address (e.g., `5.0.0.10.in-addr.arpa`). Pass it as `fqdn` of a PTR `sambadns_record` to have the hosted zone picked automatically.",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Reverse zone for `prefix_length` (e.g., `0.0.10.in-addr.arpa`).",
+			},
+			"name": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Reverse name relative to `zone`.",
+			},
+		},
+	}
+}
+
+// reverseName returns the in-addr.arpa or ip6.arpa name of an address, without the trailing dot
+// IPv6 addresses are written as 32 nibble labels, least significant first
+func reverseName(ip net.IP) string {
+	return strings.Join(append(reverseLabels(ip), reverseSuffix(ip)), ".")
+}
+
+// reverseLabels returns the labels of an address's reverse name, most specific first
+func reverseLabels(ip net.IP) []string {
+	var labels []string
+	if v4 := ip.To4(); v4 != nil {
+		for i := len(v4) - 1; i >= 0; i-- {
+			labels = append(labels, strconv.Itoa(int(v4[i])))
+		}
+		return labels
+	}
+	v6 := ip.To16()
+	for i := len(v6) - 1; i >= 0; i-- {
+		labels = append(labels, strconv.FormatUint(uint64(v6[i]&0x0f), 16), strconv.FormatUint(uint64(v6[i]>>4), 16))
+	}
+	return labels
+}
+
+func reverseSuffix(ip net.IP) string {
+	if ip.To4() != nil {
+		return "in-addr.arpa"
+	}
+	return "ip6.arpa"
+}
+
+// reverseZone returns the reverse zone covering ip at prefixLen and ip's name relative to it
+func reverseZone(ip net.IP, prefixLen int) (zone, name string, err error) {
+	bitsPerLabel, maxBits := 4, 128
+	if ip.To4() != nil {
+		bitsPerLabel, maxBits = 8, 32
+	}
+	if prefixLen <= 0 || prefixLen >= maxBits || prefixLen%bitsPerLabel != 0 {
+		return "", "", fmt.Errorf("prefix length %d does not fall on a label boundary: reverse zones for this address family need a multiple of %d below %d "+
+			"(classless delegation as in RFC 2317 is not supported)", prefixLen, bitsPerLabel, maxBits)
+	}
+
+	labels := reverseLabels(ip)
+	hostLabels := len(labels) - prefixLen/bitsPerLabel
+	zone = strings.Join(append(labels[hostLabels:], reverseSuffix(ip)), ".")
+	name = strings.Join(labels[:hostLabels], ".")
+	return zone, name, nil
+}
+
+func dataSourceReverseNameRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	address := d.Get("address").(string)
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return diag.Errorf("%q is not an IP address", address)
+	}
+
+	prefixLen := d.Get("prefix_length").(int)
+	if prefixLen == 0 {
+		prefixLen = 64
+		if ip.To4() != nil {
+			prefixLen = 24
+		}
+	}
+
+	zone, name, err := reverseZone(ip, prefixLen)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%s/%d", ip.String(), prefixLen))
+	state := newStateSetter(d)
+	state.set("fqdn", reverseName(ip))
+	state.set("zone", zone)
+	state.set("name", name)
+
+	return state.diags
+}
@@ -0,0 +1,226 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// dataSourceZoneDelegationCheckExample is the example configuration shown in the data source documentation
+const dataSourceZoneDelegationCheckExample = `
+data "sambadns_zone_delegation_check" "lab" {
+  server      = "dc01.example.com"
+  parent_zone = "example.com"
+  child_zone  = "lab.example.com"
+}
+
+resource "terraform_data" "lab_ready" {
+  lifecycle {
+    precondition {
+      condition     = data.sambadns_zone_delegation_check.lab.delegation_ok
+      error_message = "lab.example.com delegation is broken"
+    }
+  }
+}
+`
+
+func dataSourceZoneDelegationCheck() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Verifies the delegation of a child zone: NS records in the parent zone, glue for in-bailiwick "+
+			"nameservers, and authoritative answers from every listed nameserver. Intended for preconditions.",
+			dataSourceZoneDelegationCheckExample, ""),
+
+		ReadContext: wrapCRUD(dataSourceZoneDelegationCheckRead),
+
+		Schema: map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "DNS server hostname hosting the parent zone (e.g., dns.example.com).",
+			},
+			"parent_zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Parent zone holding the delegation (e.g., example.com).",
+			},
+			"child_zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Delegated child zone (e.g., lab.example.com).",
+			},
+			"timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "5s",
+				ValidateFunc: validateDuration,
+				Description:  "Timeout for each nameserver query.",
+			},
+			// Computed attributes
+			"ns_present": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the parent zone has NS records for the child.",
+			},
+			"glue_ok": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether every in-bailiwick nameserver has glue A/AAAA records in the parent.",
+			},
+			"authoritative_ok": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether every nameserver answered authoritatively for the child zone.",
+			},
+			"delegation_ok": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether all of the checks passed.",
+			},
+			"nameservers": {
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Per-nameserver results.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Nameserver hostname from the NS record.",
+						},
+						"in_bailiwick": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the nameserver lies inside the child zone and so needs glue.",
+						},
+						"glue_addresses": {
+							Type:        schema.TypeList,
+							Computed:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString},
+							Description: "Glue addresses found in the parent zone.",
+						},
+						"authoritative": {
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the nameserver answered authoritatively.",
+						},
+						"error": {
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Why the nameserver check failed, empty on success.",
+						},
+					},
+				},
+			},
+			"profile":     profileSchema(),
+			"credentials": credentialsSchema(),
+		},
+	}
+}
+
+func dataSourceZoneDelegationCheckRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	parent := strings.TrimSuffix(d.Get("parent_zone").(string), ".")
+	child := strings.TrimSuffix(d.Get("child_zone").(string), ".")
+	timeout, _ := time.ParseDuration(d.Get("timeout").(string))
+
+	label, ok := relativeName(child, parent)
+	if !ok {
+		return diag.Errorf("child_zone %s is not inside parent_zone %s", child, parent)
+	}
+
+	records, err := c.QueryName(server, parent, label)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to query delegation: %w", err))
+	}
+
+	var nameservers []string
+	for _, r := range records {
+		if r.Type == "NS" {
+			nameservers = append(nameservers, strings.TrimSuffix(r.Value, "."))
+		}
+	}
+
+	glueOK := true
+	authoritativeOK := len(nameservers) > 0
+	var results []interface{}
+
+	for _, ns := range nameservers {
+		result := map[string]interface{}{
+			"name":          ns,
+			"in_bailiwick":  false,
+			"authoritative": false,
+			"error":         "",
+		}
+
+		var glue []string
+		if nsLabel, inside := relativeName(ns, child); inside && nsLabel != "@" {
+			result["in_bailiwick"] = true
+			glueName, _ := relativeName(ns, parent)
+			glueRecords, err := c.QueryName(server, parent, glueName)
+			if err != nil {
+				return diag.FromErr(fmt.Errorf("failed to query glue for %s: %w", ns, err))
+			}
+			for _, r := range glueRecords {
+				if r.Type == "A" || r.Type == "AAAA" {
+					glue = append(glue, r.Value)
+				}
+			}
+			if len(glue) == 0 {
+				glueOK = false
+				result["error"] = "in-bailiwick nameserver has no glue records in the parent zone"
+			}
+		}
+		result["glue_addresses"] = glue
+
+		addresses := glue
+		if len(addresses) == 0 {
+			addresses, err = net.DefaultResolver.LookupHost(ctx, ns)
+			if err != nil && result["error"] == "" {
+				result["error"] = fmt.Sprintf("cannot resolve nameserver: %s", err)
+			}
+		}
+
+		authoritative := false
+		for _, addr := range addresses {
+			aa, err := queryAuthoritative(ctx, addr, child, timeout)
+			if err != nil {
+				if result["error"] == "" {
+					result["error"] = err.Error()
+				}
+				continue
+			}
+			if aa {
+				authoritative = true
+				break
+			}
+		}
+		if !authoritative && result["error"] == "" {
+			result["error"] = "answer is not authoritative (lame delegation)"
+		}
+		result["authoritative"] = authoritative
+		authoritativeOK = authoritativeOK && authoritative
+
+		results = append(results, result)
+	}
+
+	nsPresent := len(nameservers) > 0
+	d.SetId(fmt.Sprintf("%s/%s/%s", server, parent, child))
+	state := newStateSetter(d)
+	state.set("ns_present", nsPresent)
+	state.set("glue_ok", glueOK)
+	state.set("authoritative_ok", authoritativeOK)
+	state.set("delegation_ok", nsPresent && glueOK && authoritativeOK)
+	state.set("nameservers", results)
+
+	return state.diags
+}
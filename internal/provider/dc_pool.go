@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// circuitBreakerSchema returns the provider-level circuit breaker block for dns_servers
+func circuitBreakerSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "When a DC in `dns_servers` fails, stop sending it operations for a while. Without this block the defaults apply.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"failure_threshold": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      3,
+					ValidateFunc: validation.IntAtLeast(1),
+					Description:  "Consecutive failed calls (timeouts, refused or reset connections) after which the DC's circuit opens.",
+				},
+				"cooldown": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "1m",
+					ValidateFunc: validateDuration,
+					Description:  "How long an open circuit routes the DC's operations elsewhere before the DC is tried again.",
+				},
+			},
+		},
+	}
+}
+
+// dcHealth is what the pool knows about one DC during the run
+type dcHealth struct {
+	calls, failedCalls int
+	consecutive        int
+	openUntil          time.Time
+	// routedTo is the DC operations go to while the circuit is open, so each switch is logged once
+	routedTo string
+}
+
+// dcPool routes operations away from DCs whose circuit breaker is open
+// The DCs of a pool host the same AD-integrated zones, so an operation for one can run
+// against another; resources keep their configured server in state and IDs.
+// Clients cloned for per-resource credentials share the parent's pool
+type dcPool struct {
+	servers   []string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu     sync.Mutex
+	health map[string]*dcHealth
+}
+
+// newDCPool builds a pool from dns_servers and a circuit_breaker block
+func newDCPool(servers []string, raw []interface{}) *dcPool {
+	p := &dcPool{
+		servers:   servers,
+		threshold: 3,
+		cooldown:  time.Minute,
+		now:       time.Now,
+		health:    make(map[string]*dcHealth),
+	}
+	if len(raw) > 0 && raw[0] != nil {
+		cfg := raw[0].(map[string]interface{})
+		p.threshold = cfg["failure_threshold"].(int)
+		// Validated by the schema
+		p.cooldown, _ = time.ParseDuration(cfg["cooldown"].(string))
+	}
+	return p
+}
+
+// member returns the pool's spelling of server, or "" when it is not in the pool
+func (p *dcPool) member(server string) string {
+	for _, s := range p.servers {
+		if strings.EqualFold(s, server) {
+			return s
+		}
+	}
+	return ""
+}
+
+func (p *dcPool) healthOf(server string) *dcHealth {
+	key := strings.ToLower(server)
+	h := p.health[key]
+	if h == nil {
+		h = &dcHealth{}
+		p.health[key] = h
+	}
+	return h
+}
+
+// route returns the DC to run an operation for server against
+// A DC whose cooldown has passed gets the next operation again; one more failure reopens its circuit
+func (p *dcPool) route(server string) string {
+	if p == nil {
+		return server
+	}
+	requested := p.member(server)
+	if requested == "" {
+		return server
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	h := p.healthOf(requested)
+	if !h.openUntil.After(now) {
+		return server
+	}
+	for _, s := range p.servers {
+		if !p.healthOf(s).openUntil.After(now) {
+			if h.routedTo != s {
+				log.Printf("[WARN] circuit for %s is open, routing its operations to %s", requested, s)
+				h.routedTo = s
+			}
+			return s
+		}
+	}
+	// Every circuit is open: the requested DC is as good as any
+	return server
+}
+
+// report records the outcome of a call against server
+// Only failures reaching the DC count; samba-tool errors the DC answered with show it is up
+func (p *dcPool) report(server string, err error) {
+	if p == nil {
+		return
+	}
+	member := p.member(server)
+	if member == "" {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	h := p.healthOf(member)
+	h.calls++
+	if err == nil || retryReason(err.Error()) == "" {
+		if h.consecutive >= p.threshold {
+			log.Printf("[INFO] %s answered again, closing its circuit", member)
+		}
+		h.consecutive = 0
+		h.openUntil = time.Time{}
+		h.routedTo = ""
+		return
+	}
+	h.failedCalls++
+	h.consecutive++
+	if h.consecutive >= p.threshold {
+		h.openUntil = p.now().Add(p.cooldown)
+		log.Printf("[WARN] opening the circuit for %s for %s after %d consecutive failures (%d of %d calls failed in this run): %s",
+			member, p.cooldown, h.consecutive, h.failedCalls, h.calls, retryReason(err.Error()))
+	}
+}
+
+// routeArgs returns samba-tool arguments with the dns subcommand's server routed through the pool
+func (c *SambaClient) routeArgs(args []string) ([]string, string) {
+	if c.pool == nil || len(args) < 3 || args[0] != "dns" {
+		return args, ""
+	}
+	server := c.pool.route(args[2])
+	if server == args[2] {
+		return args, server
+	}
+	out := append([]string{}, args...)
+	out[2] = server
+	return out, server
+}
+
+// validateDNSServers rejects duplicate pool members
+func validateDNSServers(raw []interface{}) error {
+	seen := make(map[string]bool, len(raw))
+	for _, v := range raw {
+		s := strings.ToLower(v.(string))
+		if seen[s] {
+			return fmt.Errorf("dns_servers lists %s twice", v)
+		}
+		seen[s] = true
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// renamedAttribute is a top-level attribute whose name changed
+// The old name keeps working, marked deprecated, for at least one major version. Code keeps
+// reading the old name: plans, reads and state upgrades fill in both, so either may be configured
+type renamedAttribute struct {
+	from, to string
+	// removal is the release that drops the old name
+	removal string
+}
+
+var renamedAttributes = []renamedAttribute{
+	{from: "dns_server", to: "server", removal: "v2.0.0"},
+}
+
+// applyRenames adds the new name of every renamed attribute a resource or data source has
+// Resources get a CustomizeDiff shim copying the configured name to the other one, so neither shows
+// a diff, a Read hook for imports, and a state upgrade filling the new name into existing state.
+// Data sources copy the configured name to the other one before reading
+func applyRenames(r *schema.Resource, managed bool) {
+	var renames []renamedAttribute
+	for _, rename := range renamedAttributes {
+		if _, ok := r.Schema[rename.from]; ok {
+			renames = append(renames, rename)
+		}
+	}
+	if len(renames) == 0 {
+		return
+	}
+
+	if managed {
+		// Upgrades read state with the schema as it was before the new names existed
+		prior := &schema.Resource{Schema: make(map[string]*schema.Schema, len(r.Schema))}
+		for k, v := range r.Schema {
+			prior.Schema[k] = v
+		}
+		r.StateUpgraders = append(r.StateUpgraders, schema.StateUpgrader{
+			Version: r.SchemaVersion,
+			Type:    prior.CoreConfigSchema().ImpliedType(),
+			Upgrade: upgradeRenamedState(renames),
+		})
+		r.SchemaVersion++
+	}
+
+	for _, rename := range renames {
+		old := r.Schema[rename.from]
+		renamed := *old
+		for _, s := range []*schema.Schema{old, &renamed} {
+			s.Required = false
+			s.Optional = true
+			s.Computed = true
+			s.ExactlyOneOf = []string{rename.from, rename.to}
+		}
+		old.Deprecated = fmt.Sprintf("Use `%s` instead. `%s` will be removed in %s.", rename.to, rename.from, rename.removal)
+		old.Description = fmt.Sprintf("Deprecated alias of `%s`.", rename.to)
+		r.Schema[rename.to] = &renamed
+	}
+
+	read := r.ReadContext
+	if !managed {
+		r.ReadContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			state := newStateSetter(d)
+			for _, rename := range renames {
+				if v := d.Get(rename.to).(string); v != "" {
+					state.set(rename.from, v)
+				} else {
+					state.set(rename.to, d.Get(rename.from))
+				}
+			}
+			return append(state.diags, read(ctx, d, m)...)
+		}
+		return
+	}
+
+	r.ReadContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		diags := read(ctx, d, m)
+		if d.Id() == "" {
+			return diags
+		}
+		// Reads set the old name; imports and refreshes carry it over
+		state := newStateSetter(d)
+		for _, rename := range renames {
+			state.set(rename.to, d.Get(rename.from))
+		}
+		return append(diags, state.diags...)
+	}
+
+	shim := syncRenamedAttributes(renames)
+	if customize := r.CustomizeDiff; customize != nil {
+		// The shim runs first, so the resource's own checks see the old name filled in
+		r.CustomizeDiff = func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+			if err := shim(ctx, d, m); err != nil {
+				return err
+			}
+			return customize(ctx, d, m)
+		}
+	} else {
+		r.CustomizeDiff = shim
+	}
+}
+
+// syncRenamedAttributes plans the name that is not configured with the value of the one that is
+func syncRenamedAttributes(renames []renamedAttribute) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		config := d.GetRawConfig()
+		for _, rename := range renames {
+			from, to := rename.from, rename.to
+			if !config.IsNull() && !config.GetAttr(to).IsNull() {
+				from, to = to, from
+			}
+			if !d.NewValueKnown(from) {
+				if err := d.SetNewComputed(to); err != nil {
+					return err
+				}
+				continue
+			}
+			if d.Get(to) == d.Get(from) {
+				continue
+			}
+			if err := d.SetNew(to, d.Get(from)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// upgradeRenamedState copies the old name of renamed attributes to the new one in existing state
+func upgradeRenamedState(renames []renamedAttribute) schema.StateUpgradeFunc {
+	return func(ctx context.Context, rawState map[string]interface{}, meta interface{}) (map[string]interface{}, error) {
+		if rawState == nil {
+			return rawState, nil
+		}
+		for _, rename := range renames {
+			if v, ok := rawState[rename.from]; ok {
+				if current, _ := rawState[rename.to].(string); current == "" {
+					rawState[rename.to] = v
+				}
+			}
+		}
+		return rawState, nil
+	}
+}
@@ -0,0 +1,140 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// crudFunc is the common signature of resource and data source CRUD functions
+type crudFunc = func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics
+
+// wrapCRUD attaches per-operation state to a CRUD function and post-processes its diagnostics
+func wrapCRUD(f crudFunc) crudFunc {
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		log := &retryLog{}
+		serials := &serialWarnings{}
+		ctx = context.WithValue(context.WithValue(ctx, retryLogKey{}, log), serialWarningsKey{}, serials)
+		diags := f(ctx, d, m)
+		diags = reportRetries(log, diags)
+		diags = reportSerialWarnings(serials, diags)
+		if a, ok := m.(*apiClient); ok && a.failures != nil {
+			diags = a.failures.debounce(diags)
+		}
+		return diags
+	}
+}
+
+// failureTracker collapses identical backend failures seen across resources in one run
+// When a DC is down, the first resource reports the full samba-tool error and every
+// later resource hitting the same error gets a one-line reference to it
+type failureTracker struct {
+	mu   sync.Mutex
+	seen map[string]int
+}
+
+// newFailureTracker creates an empty failureTracker
+func newFailureTracker() *failureTracker {
+	return &failureTracker{seen: make(map[string]int)}
+}
+
+// debounce shortens error diagnostics whose samba-tool failure was already reported
+func (t *failureTracker) debounce(diags diag.Diagnostics) diag.Diagnostics {
+	for i := range diags {
+		if diags[i].Severity != diag.Error {
+			continue
+		}
+		key := backendFailureKey(diags[i].Summary)
+		if key == "" {
+			continue
+		}
+
+		t.mu.Lock()
+		t.seen[key]++
+		count := t.seen[key]
+		t.mu.Unlock()
+
+		if count == 1 {
+			diags[i].Detail = strings.TrimSpace(diags[i].Detail + "\n\nOther resources failing with the same samba-tool error are reported in one line.")
+			continue
+		}
+
+		prefix, _, _ := strings.Cut(diags[i].Summary, "samba-tool error:")
+		diags[i].Summary = fmt.Sprintf("%ssame samba-tool failure as reported above (%s, occurrence %d)",
+			prefix, shortFailureReason(key), count)
+		diags[i].Detail = ""
+	}
+	return diags
+}
+
+// backendFailureKey extracts the samba-tool stderr from an error message, or "" if there is none
+func backendFailureKey(msg string) string {
+	_, stderr, found := strings.Cut(msg, "stderr:")
+	if !found {
+		return ""
+	}
+	return strings.TrimSpace(stderr)
+}
+
+// shortFailureReason condenses samba-tool stderr to its status code or first line
+func shortFailureReason(stderr string) string {
+	if m := ntStatusRegex.FindString(stderr); m != "" {
+		return m
+	}
+	line, _, _ := strings.Cut(stderr, "\n")
+	return strings.TrimSpace(line)
+}
+
+// stateSetter writes attributes to state and collects the errors d.Set returns
+// An informational attribute that cannot be stored is cleared rather than left at the value from the
+// previous state generation, and the remaining attributes are still written, so one malformed value
+// neither hides behind stale state nor throws away the rest of a refresh. An identity attribute keeps
+// its previous value and fails the read, since clearing it would plan a replacement
+type stateSetter struct {
+	d     *schema.ResourceData
+	diags diag.Diagnostics
+}
+
+func newStateSetter(d *schema.ResourceData) *stateSetter {
+	return &stateSetter{d: d}
+}
+
+// identityAttributes name the object in every schema that has them; they are all the ForceNew attributes
+var identityAttributes = map[string]bool{
+	"dns_server":             true,
+	"zone":                   true,
+	"name":                   true,
+	"type":                   true,
+	"directory_partition":    true,
+	"directory_partition_dn": true,
+}
+
+// set sets key to value, recording a warning when the value does not fit the schema, or an error
+// when key is an identity attribute
+func (s *stateSetter) set(key string, value interface{}) {
+	err := s.d.Set(key, value)
+	if err == nil {
+		return
+	}
+	if identityAttributes[key] {
+		s.diags = append(s.diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Could not store %s in state", key),
+			Detail:   fmt.Sprintf("%v\n\n%s identifies the object, so it keeps its previous value rather than being cleared and planning a replacement.", err, key),
+		})
+		return
+	}
+	detail := fmt.Sprintf("%v\n\n%s was cleared instead of keeping its previous value; the next plan may show it changing.", err, key)
+	if clearErr := s.d.Set(key, nil); clearErr != nil {
+		detail = fmt.Sprintf("%v\n\nClearing %s failed too (%v), so state may still hold its previous value.", err, key, clearErr)
+	}
+	s.diags = append(s.diags, diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  fmt.Sprintf("Could not store %s in state", key),
+		Detail:   detail,
+	})
+}
@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// TestStateSetterIdentity checks that an identity attribute that cannot be stored fails the read and
+// keeps its value, while an informational one is cleared with a warning
+func TestStateSetterIdentity(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceRecord().Schema, map[string]interface{}{
+		"dns_server": "dc",
+		"zone":       "example.com",
+		"name":       "web",
+		"type":       "A",
+		"value":      "10.0.0.1",
+	})
+	if err := d.Set("rank", "zone"); err != nil {
+		t.Fatal(err)
+	}
+
+	state := newStateSetter(d)
+	state.set("name", map[string]interface{}{"bad": 1})
+	if len(state.diags) != 1 || state.diags[0].Severity != diag.Error {
+		t.Fatalf("identity attribute: got %v, want one error", state.diags)
+	}
+	if got := d.Get("name").(string); got != "web" {
+		t.Errorf("name = %q after a failed set, want it kept as web", got)
+	}
+
+	state = newStateSetter(d)
+	state.set("rank", map[string]interface{}{"bad": 1})
+	if len(state.diags) != 1 || state.diags[0].Severity != diag.Warning {
+		t.Fatalf("informational attribute: got %v, want one warning", state.diags)
+	}
+	if got := d.Get("rank").(string); got != "" {
+		t.Errorf("rank = %q after a failed set, want it cleared", got)
+	}
+}
@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Names are handled in RFC 1035 presentation format: a backslash makes the next character literal,
+// so `\.` is a dot inside a label and `\\` a backslash, or starts a three digit decimal byte, e.g. `\047`.
+// Names are passed to samba-tool as written; the helpers below split, compare and encode them
+// label by label, so a label such as `selector\.sub` in `selector\.sub._domainkey` stays one label
+
+// escapedAt reports whether the character at s[i] is escaped by the backslashes before it
+func escapedAt(s string, i int) bool {
+	n := 0
+	for j := i - 1; j >= 0 && s[j] == '\\'; j-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// trimRoot drops the trailing dot of an absolute name, unless the dot is escaped
+func trimRoot(name string) string {
+	if strings.HasSuffix(name, ".") && !escapedAt(name, len(name)-1) {
+		return name[:len(name)-1]
+	}
+	return name
+}
+
+// hasRoot reports whether a name ends with an unescaped dot
+func hasRoot(name string) bool {
+	return trimRoot(name) != name
+}
+
+// splitLabels splits a name at its unescaped dots, keeping the escapes in each label
+// The trailing dot of an absolute name is dropped first, and "" has no labels
+func splitLabels(name string) []string {
+	name = trimRoot(name)
+	if name == "" {
+		return nil
+	}
+	var labels []string
+	start := 0
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case '\\':
+			i++
+		case '.':
+			labels = append(labels, name[start:i])
+			start = i + 1
+		}
+	}
+	return append(labels, name[start:])
+}
+
+// decodeLabel returns the bytes a label in presentation format stands for
+func decodeLabel(label string) ([]byte, error) {
+	b := make([]byte, 0, len(label))
+	for i := 0; i < len(label); i++ {
+		if label[i] != '\\' {
+			b = append(b, label[i])
+			continue
+		}
+		if i+1 == len(label) {
+			return nil, fmt.Errorf("label %q ends with an unfinished escape", label)
+		}
+		if !isDigit(label[i+1]) {
+			b = append(b, label[i+1])
+			i++
+			continue
+		}
+		if i+3 >= len(label) || !isDigit(label[i+2]) || !isDigit(label[i+3]) {
+			return nil, fmt.Errorf("label %q has an escape that is not three decimal digits", label)
+		}
+		v := int(label[i+1]-'0')*100 + int(label[i+2]-'0')*10 + int(label[i+3]-'0')
+		if v > 255 {
+			return nil, fmt.Errorf("label %q escapes a value above 255", label)
+		}
+		b = append(b, byte(v))
+		i += 3
+	}
+	return b, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// encodeLabel writes label bytes in presentation format, escaping dots and backslashes with a
+// backslash and bytes outside printable ASCII as \DDD
+func encodeLabel(b []byte) string {
+	var s strings.Builder
+	for _, c := range b {
+		switch {
+		case c == '.' || c == '\\':
+			s.WriteByte('\\')
+			s.WriteByte(c)
+		case c <= ' ' || c >= 0x7f:
+			fmt.Fprintf(&s, "\\%03d", c)
+		default:
+			s.WriteByte(c)
+		}
+	}
+	return s.String()
+}
+
+// canonicalLabel returns the one spelling of a label that equal labels share,
+// or the label unchanged when its escapes are invalid
+func canonicalLabel(label string) string {
+	b, err := decodeLabel(label)
+	if err != nil {
+		return label
+	}
+	return encodeLabel(b)
+}
+
+// canonicalName is a name with every label in canonical spelling and without the trailing dot
+// It keeps case; compare with strings.EqualFold for DNS name equality
+func canonicalName(name string) string {
+	labels := splitLabels(name)
+	for i, l := range labels {
+		labels[i] = canonicalLabel(l)
+	}
+	return strings.Join(labels, ".")
+}
+
+// sameName reports whether two names are equal, ignoring case and the spelling of escapes
+func sameName(a, b string) bool {
+	return strings.EqualFold(canonicalName(a), canonicalName(b))
+}
+
+// validateDNSName rejects names whose escapes are invalid or whose labels do not fit the wire format
+func validateDNSName(v interface{}, k string) (warnings []string, errs []error) {
+	name := v.(string)
+	if name == "" || name == "@" {
+		return nil, nil
+	}
+	for _, label := range splitLabels(name) {
+		b, err := decodeLabel(label)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("%q: %w", k, err))
+		case len(b) == 0:
+			errs = append(errs, fmt.Errorf("%q: name %q has an empty label", k, name))
+		case len(b) > 63:
+			errs = append(errs, fmt.Errorf("%q: label %q is longer than 63 bytes", k, label))
+		}
+	}
+	return warnings, errs
+}
+
+// suppressEquivalentName suppresses diffs between spellings of the same name, e.g. `\047` and `/`
+// Case is kept significant, so changing it still renames the record
+func suppressEquivalentName(k, old, new string, d *schema.ResourceData) bool {
+	return old != "" && canonicalName(old) == canonicalName(new)
+}
+
+// idComponent is a zone or name as written into a resource ID
+// Slashes separate the components, so a slash in a name is written as its RFC 1035 escape `\047`
+func idComponent(s string) string {
+	if !strings.Contains(s, "/") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '/':
+			b.WriteString(`\047`)
+		case s[i] == '\\' && i+1 < len(s) && s[i+1] == '/':
+			b.WriteString(`\047`)
+			i++
+		case s[i] == '\\' && i+1 < len(s):
+			b.WriteString(s[i : i+2])
+			i++
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// fromIDComponent is the inverse of idComponent
+func fromIDComponent(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && !escapedAt(s, i) && strings.HasPrefix(s[i:], `\047`) {
+			b.WriteByte('/')
+			i += 3
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"time"
+)
+
+// DNS wire constants used by the authoritative probe
+const (
+	dnsTypeSOA   = 6
+	dnsClassIN   = 1
+	dnsFlagAA    = 0x0400
+	dnsFlagQR    = 0x8000
+	dnsRcodeMask = 0x000f
+)
+
+// queryAuthoritative sends a non-recursive SOA query for zone to a nameserver and reports
+// whether the server answered authoritatively (AA bit set, rcode NOERROR)
+// net.Resolver hides the header flags, so the query is built by hand
+func queryAuthoritative(ctx context.Context, server, zone string, timeout time.Duration) (bool, error) {
+	msg, id, err := buildDNSQuery(zone, dnsTypeSOA)
+	if err != nil {
+		return false, err
+	}
+
+	dialer := net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "udp", net.JoinHostPort(server, "53"))
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return false, err
+	}
+	if _, err := conn.Write(msg); err != nil {
+		return false, err
+	}
+
+	buf := make([]byte, 512)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return false, err
+	}
+	if n < 12 {
+		return false, fmt.Errorf("short DNS response from %s", server)
+	}
+	if binary.BigEndian.Uint16(buf[0:2]) != id {
+		return false, fmt.Errorf("mismatched DNS response id from %s", server)
+	}
+
+	flags := binary.BigEndian.Uint16(buf[2:4])
+	if flags&dnsFlagQR == 0 {
+		return false, fmt.Errorf("%s did not send a DNS response", server)
+	}
+	if rcode := flags & dnsRcodeMask; rcode != 0 {
+		return false, fmt.Errorf("%s answered with rcode %d", server, rcode)
+	}
+	return flags&dnsFlagAA != 0, nil
+}
+
+// buildDNSQuery encodes a single-question query with recursion not desired
+func buildDNSQuery(name string, qtype uint16) ([]byte, uint16, error) {
+	var idBytes [2]byte
+	if _, err := rand.Read(idBytes[:]); err != nil {
+		return nil, 0, err
+	}
+	id := binary.BigEndian.Uint16(idBytes[:])
+
+	msg := make([]byte, 12, 512)
+	binary.BigEndian.PutUint16(msg[0:2], id)
+	binary.BigEndian.PutUint16(msg[4:6], 1) // QDCOUNT
+
+	for _, escaped := range splitLabels(name) {
+		label, err := decodeLabel(escaped)
+		if err != nil || len(label) == 0 || len(label) > 63 {
+			return nil, 0, fmt.Errorf("invalid DNS name %q", name)
+		}
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0)
+	msg = append(msg, byte(qtype>>8), byte(qtype), 0, dnsClassIN)
+
+	return msg, id, nil
+}
@@ -0,0 +1,21 @@
+package provider
+
+import "strings"
+
+// docDescription returns a resource or data source description followed by its example usage
+// terraform-plugin-docs renders the description at the top of the registry page, so examples live
+// next to the schema they document and are generated with it (go generate at the repository root).
+// importCommand is the terraform import line for resources that support import, "" otherwise
+func docDescription(description, example, importCommand string) string {
+	var b strings.Builder
+	b.WriteString(description)
+	b.WriteString("\n\n## Example Usage\n\n```terraform\n")
+	b.WriteString(strings.TrimSpace(example))
+	b.WriteString("\n```")
+	if importCommand != "" {
+		b.WriteString("\n\n## Import\n\n```shell\n")
+		b.WriteString(importCommand)
+		b.WriteString("\n```")
+	}
+	return b.String()
+}
@@ -0,0 +1,512 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fileZone is a zone held by the file backend
+type fileZone struct {
+	Partition string       `json:"partition"`
+	Records   []fileRecord `json:"records"`
+	// Forwarders make the zone a conditional forwarder zone
+	Forwarders *fileForwarders `json:"forwarders,omitempty"`
+}
+
+// fileForwarders are the forwarder settings of the server or of a conditional forwarder zone
+type fileForwarders struct {
+	Addresses      []string `json:"addresses"`
+	Timeout        int      `json:"timeout,omitempty"`
+	ForwardersOnly bool     `json:"forwarders_only,omitempty"`
+}
+
+// fileRecord is a record held by the file backend, with its value in samba-tool add format
+type fileRecord struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+	TTL   int    `json:"ttl,omitempty"`
+	// Flags are the record's dwFlags in hex, f0 (zone data) when empty
+	Flags string `json:"flags,omitempty"`
+	// Timestamp marks a dynamic record, as an RFC 3339 time of its last refresh
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// fileState is the content of a backend file
+//
+//	{
+//	  "zones": {
+//	    "example.com": {
+//	      "partition": "domain",
+//	      "records": [{"name": "web", "type": "A", "value": "10.0.0.5", "ttl": 900}]
+//	    }
+//	  }
+//	}
+type fileState struct {
+	Zones      map[string]*fileZone `json:"zones"`
+	Forwarders *fileForwarders      `json:"forwarders,omitempty"`
+}
+
+// fileRunner answers samba-tool dns invocations from a local JSON file instead of a DC
+// Writes change the file, so plans and applies work offline; the server argument is ignored,
+// since the file stands for a single DC's view of its zones
+type fileRunner struct {
+	path string
+	mu   sync.Mutex
+}
+
+// errFileBackend is returned with samba-tool style stderr when an emulated command fails
+var errFileBackend = errors.New("file backend: command failed")
+
+func (r *fileRunner) run(command, args, auth []string) (string, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(args) < 3 || args[0] != "dns" {
+		return "", "ERROR: not supported by the file backend", fmt.Errorf("file backend: samba-tool %s is not supported", strings.Join(args, " "))
+	}
+
+	state, err := r.load()
+	if err != nil {
+		return "", "", err
+	}
+
+	stdout, stderr, changed := state.apply(args[1], args[2], args[3:])
+	if stderr != "" {
+		return "", stderr, errFileBackend
+	}
+	if changed {
+		if err := r.save(state); err != nil {
+			return "", "", err
+		}
+	}
+	return stdout, "", nil
+}
+
+// writeRecordFlags sets the flags of a stored record, which samba-tool has no command for
+func (r *fileRunner) writeRecordFlags(server, zone, name, recordType, value string, flags uint32) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, err := r.load()
+	if err != nil {
+		return err
+	}
+	z := state.Zones[strings.ToLower(zone)]
+	if z == nil {
+		return fmt.Errorf("file backend: zone %s does not exist", zone)
+	}
+	i := z.find(name, recordType, value)
+	if i < 0 {
+		return fmt.Errorf("file backend: %s %s %s does not exist in zone %s", name, recordType, value, zone)
+	}
+	z.Records[i].Flags = fmt.Sprintf("%x", flags)
+	return r.save(state)
+}
+
+// writeForwarders sets the forwarder settings of the server, or of a conditional forwarder zone
+// Zones become forwarder zones by editing the file; a primary zone is not turned into one
+func (r *fileRunner) writeForwarders(server, zone string, s forwarderSettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, err := r.load()
+	if err != nil {
+		return err
+	}
+	forwarders := &fileForwarders{Addresses: s.Forwarders, Timeout: s.Timeout, ForwardersOnly: s.ForwardersOnly}
+	if zone == "" {
+		state.Forwarders = forwarders
+		return r.save(state)
+	}
+	z := state.Zones[strings.ToLower(zone)]
+	if z == nil {
+		return fmt.Errorf("file backend: zone %s does not exist", zone)
+	}
+	if z.Forwarders == nil {
+		return fmt.Errorf("file backend: zone %s is not a conditional forwarder zone", zone)
+	}
+	z.Forwarders = forwarders
+	return r.save(state)
+}
+
+// settings returns the forwarder settings as serverinfo and zoneinfo report them
+func (f *fileForwarders) settings() forwarderSettings {
+	s := forwarderSettings{Forwarders: []string{}, Timeout: defaultForwardTimeout}
+	if f == nil {
+		return s
+	}
+	s.Forwarders = f.Addresses
+	s.ForwardersOnly = f.ForwardersOnly
+	if f.Timeout != 0 {
+		s.Timeout = f.Timeout
+	}
+	return s
+}
+
+// load reads the backend file; a missing file is an empty server
+func (r *fileRunner) load() (*fileState, error) {
+	state := &fileState{}
+	data, err := os.ReadFile(r.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("file backend: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("file backend: %s is not valid JSON: %w", r.path, err)
+		}
+	}
+	if state.Zones == nil {
+		state.Zones = make(map[string]*fileZone)
+	}
+	return state, nil
+}
+
+// save writes the backend file through a temporary file, so an interrupted apply leaves the old content
+func (r *fileRunner) save(state *fileState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("file backend: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), filepath.Base(r.path)+".*")
+	if err != nil {
+		return fmt.Errorf("file backend: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("file backend: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("file backend: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), r.path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("file backend: %w", err)
+	}
+	return nil
+}
+
+// apply runs one dns subcommand against the state
+// stderr is set, in the words samba-tool uses, when the command fails
+func (s *fileState) apply(subcommand, server string, args []string) (stdout, stderr string, changed bool) {
+	switch subcommand {
+	case "serverinfo":
+		forwarders := s.Forwarders.settings()
+		var b strings.Builder
+		for _, field := range [][2]string{
+			{"pszServerName", server},
+			{"fDsAvailable", "TRUE"},
+			{"aipForwarders", formatAddressList(forwarders.Forwarders)},
+			{"dwForwardTimeout", strconv.Itoa(forwarders.Timeout)},
+			{"fSlave", strings.ToUpper(strconv.FormatBool(forwarders.ForwardersOnly))},
+		} {
+			fmt.Fprintf(&b, "  %-28s: %s\n", field[0], field[1])
+		}
+		return b.String(), "", false
+	case "zonelist":
+		return s.zoneList(), "", false
+	case "zonecreate":
+		if len(args) < 1 {
+			break
+		}
+		zone := strings.ToLower(args[0])
+		if s.Zones[zone] != nil {
+			return "", "ERROR: Failed to create zone: WERR_DNS_ERROR_ZONE_ALREADY_EXISTS", false
+		}
+		partition := "domain"
+		for _, arg := range args[1:] {
+			if strings.HasPrefix(arg, "--dns-directory-partition=") {
+				partition = strings.TrimPrefix(arg, "--dns-directory-partition=")
+			}
+		}
+		s.Zones[zone] = &fileZone{
+			Partition: partition,
+			Records: []fileRecord{
+				{Name: "@", Type: "SOA", Value: fmt.Sprintf("serial=1, refresh=900, retry=600, expire=86400, minttl=3600, ns=%s., email=hostmaster.%s.", server, zone), TTL: 3600},
+				{Name: "@", Type: "NS", Value: server + ".", TTL: 3600},
+			},
+		}
+		return "Zone " + zone + " created successfully", "", true
+	}
+
+	if len(args) < 1 {
+		return "", "ERROR: not supported by the file backend", false
+	}
+	zone := s.Zones[strings.ToLower(args[0])]
+	if zone == nil {
+		return "", "ERROR: WERR_DNS_ERROR_ZONE_DOES_NOT_EXIST", false
+	}
+
+	switch subcommand {
+	case "zoneinfo":
+		return zone.info(strings.ToLower(args[0])), "", false
+	case "zonedelete":
+		delete(s.Zones, strings.ToLower(args[0]))
+		return "Zone " + args[0] + " deleted successfully", "", true
+	case "query":
+		if len(args) == 3 {
+			return zone.query(args[1], strings.ToUpper(args[2]))
+		}
+	case "add":
+		if len(args) == 4 {
+			return zone.bumped(zone.add(args[1], strings.ToUpper(args[2]), args[3]))
+		}
+	case "delete":
+		if len(args) == 4 {
+			return zone.bumped(zone.remove(args[1], strings.ToUpper(args[2]), args[3]))
+		}
+	case "update":
+		if len(args) == 5 && strings.EqualFold(args[2], "SOA") {
+			// An SOA update sets the serial itself
+			return zone.update(args[1], "SOA", args[3], args[4])
+		}
+		if len(args) == 5 {
+			return zone.bumped(zone.update(args[1], strings.ToUpper(args[2]), args[3], args[4]))
+		}
+	}
+	return "", "ERROR: not supported by the file backend", false
+}
+
+func (s *fileState) zoneList() string {
+	names := make([]string, 0, len(s.Zones))
+	for name := range s.Zones {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  %d zone(s) found\n\n", len(names))
+	for _, name := range names {
+		fmt.Fprintf(&b, "  pszZoneName                 : %s\n", name)
+		b.WriteString("  Flags                       : DNS_RPC_ZONE_DSINTEGRATED DNS_RPC_ZONE_UPDATE_SECURE\n")
+		fmt.Fprintf(&b, "  ZoneType                    : %s\n\n", s.Zones[name].zoneType())
+	}
+	return b.String()
+}
+
+func (z *fileZone) info(name string) string {
+	partition, flags := "DomainDnsZones", "DNS_DP_DOMAIN_DEFAULT"
+	if z.Partition == "forest" {
+		partition, flags = "ForestDnsZones", "DNS_DP_FOREST_DEFAULT"
+	}
+	reverse := "FALSE"
+	if strings.HasSuffix(name, ".arpa") {
+		reverse = "TRUE"
+	}
+
+	fields := [][2]string{
+		{"pszZoneName", name},
+		{"dwZoneType", z.zoneType()},
+		{"fReverse", reverse},
+		{"fAllowUpdate", "DNS_ZONE_UPDATE_SECURE"},
+		{"fAging", "FALSE"},
+		{"dwNoRefreshInterval", "168"},
+		{"dwRefreshInterval", "168"},
+		{"pszDpFqdn", partition + ".file"},
+		{"pwszZoneDn", "DC=" + name + ",CN=MicrosoftDNS,DC=" + partition},
+		{"dwDpFlags", flags},
+	}
+	if z.Forwarders != nil {
+		forwarders := z.Forwarders.settings()
+		fields = append(fields,
+			[2]string{"aipMasters", formatAddressList(forwarders.Forwarders)},
+			[2]string{"dwForwarderTimeout", strconv.Itoa(forwarders.Timeout)},
+			[2]string{"fForwarderSlave", strings.ToUpper(strconv.FormatBool(forwarders.ForwardersOnly))})
+	}
+
+	var b strings.Builder
+	for _, field := range fields {
+		fmt.Fprintf(&b, "  %-28s: %s\n", field[0], field[1])
+	}
+	return b.String()
+}
+
+// zoneType is the zone type zonelist and zoneinfo report
+func (z *fileZone) zoneType() string {
+	if z.Forwarders != nil {
+		return "DNS_ZONE_TYPE_FORWARDER"
+	}
+	return "DNS_ZONE_TYPE_PRIMARY"
+}
+
+// query prints a node as samba-tool dns query does: its own records, then each child node with its records
+func (z *fileZone) query(name, recordType string) (string, string, bool) {
+	node := strings.ToLower(name)
+	own := z.records(node, recordType)
+	children := z.children(node)
+	if len(own) == 0 && (recordType != "ALL" || len(children) == 0) {
+		return "", "ERROR: WERR_DNS_ERROR_NAME_DOES_NOT_EXIST", false
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "  Name=, Records=%d, Children=%d\n", len(own), len(children))
+	writeFileRecords(&b, own)
+	if recordType == "ALL" {
+		for _, child := range children {
+			full := joinNodeName(child, node)
+			records := z.records(full, "ALL")
+			fmt.Fprintf(&b, "  Name=%s, Records=%d, Children=%d\n", child, len(records), len(z.children(full)))
+			writeFileRecords(&b, records)
+		}
+	}
+	return b.String(), "", false
+}
+
+// records returns the records at a node, of one type or ALL
+func (z *fileZone) records(node, recordType string) []fileRecord {
+	var records []fileRecord
+	for _, r := range z.Records {
+		if sameName(r.Name, node) && (recordType == "ALL" || r.Type == recordType) {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+// children returns the sorted labels of the nodes directly below node
+func (z *fileZone) children(node string) []string {
+	seen := make(map[string]bool)
+	for _, r := range z.Records {
+		if r.Name == "@" {
+			continue
+		}
+		labels := splitLabels(r.Name)
+		if node != "@" {
+			below, inside := relativeName(r.Name, node)
+			if !inside || below == "@" {
+				continue
+			}
+			labels = splitLabels(below)
+		}
+		seen[strings.ToLower(canonicalLabel(labels[len(labels)-1]))] = true
+	}
+	labels := make([]string, 0, len(seen))
+	for label := range seen {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// writeFileRecords prints records in samba-tool's line format
+func writeFileRecords(b *strings.Builder, records []fileRecord) {
+	for _, r := range records {
+		ttl := r.TTL
+		if ttl == 0 {
+			ttl = 3600
+		}
+		value := r.Value
+		fields := strings.Fields(value)
+		switch {
+		case r.Type == "MX" && len(fields) == 2:
+			value = fmt.Sprintf("%s. (%s)", strings.TrimSuffix(fields[0], "."), fields[1])
+		case r.Type == "SRV" && len(fields) == 4:
+			value = fmt.Sprintf("%s. (%s, %s, %s)", strings.TrimSuffix(fields[0], "."), fields[1], fields[2], fields[3])
+		}
+		flags := r.Flags
+		if flags == "" {
+			flags = "f0"
+		}
+		if stamp, err := time.Parse(time.RFC3339, r.Timestamp); err == nil {
+			fmt.Fprintf(b, "    %s: %s (flags=%s, serial=1, ttl=%d, timestamp=%d)\n", r.Type, value, flags, ttl, formatRecordTimestamp(stamp))
+			continue
+		}
+		fmt.Fprintf(b, "    %s: %s (flags=%s, serial=1, ttl=%d)\n", r.Type, value, flags, ttl)
+	}
+}
+
+// find returns the index of the record at name matching value, compared as the provider compares values
+// A value in samba-tool delete format (e.g., quoted TXT strings) also matches
+func (z *fileZone) find(name, recordType, value string) int {
+	for i, r := range z.Records {
+		if !sameName(r.Name, name) || r.Type != recordType {
+			continue
+		}
+		if recordValuesEqual(recordType, r.Value, value) || deleteValue(recordType, r.Value) == value {
+			return i
+		}
+	}
+	return -1
+}
+
+func (z *fileZone) add(name, recordType, value string) (string, string, bool) {
+	if z.find(name, recordType, value) >= 0 {
+		return "", "ERROR: Record already exists", false
+	}
+	if recordType == "TXT" {
+		// samba-tool splits the argument into strings and prints them quoted
+		value = canonicalTXT(value)
+	}
+	z.Records = append(z.Records, fileRecord{Name: name, Type: recordType, Value: value})
+	return "Record added successfully", "", true
+}
+
+func (z *fileZone) remove(name, recordType, value string) (string, string, bool) {
+	i := z.find(name, recordType, value)
+	if i < 0 {
+		return "", "ERROR: WERR_DNS_ERROR_RECORD_DOES_NOT_EXIST", false
+	}
+	z.Records = append(z.Records[:i], z.Records[i+1:]...)
+	return "Record deleted successfully", "", true
+}
+
+func (z *fileZone) update(name, recordType, oldValue, newValue string) (string, string, bool) {
+	if recordType == "SOA" {
+		// SOA data is given as "ns email serial refresh retry expire minttl" but printed as fields
+		oldValue, newValue = soaFieldsValue(oldValue), soaFieldsValue(newValue)
+	}
+	i := z.find(name, recordType, oldValue)
+	if i < 0 {
+		return "", "ERROR: WERR_DNS_ERROR_RECORD_DOES_NOT_EXIST", false
+	}
+	z.Records[i].Value = newValue
+	return "Record updated successfully", "", true
+}
+
+// bumped increments the zone serial after a record write changed the zone, as the DNS server does
+func (z *fileZone) bumped(stdout, stderr string, changed bool) (string, string, bool) {
+	if !changed {
+		return stdout, stderr, changed
+	}
+	for i, r := range z.Records {
+		if r.Type != "SOA" {
+			continue
+		}
+		fields, err := parseSOAValue(r.Value)
+		if err != nil {
+			break
+		}
+		serial, err := strconv.ParseUint(fields["serial"], 10, 32)
+		if err != nil {
+			break
+		}
+		fields["serial"] = strconv.FormatUint(uint64(uint32(serial)+1), 10)
+		z.Records[i].Value = soaFieldsValue(formatSOAData(fields))
+		break
+	}
+	return stdout, stderr, changed
+}
+
+// soaFieldsValue converts SOA record data to the form samba-tool dns query prints it in
+func soaFieldsValue(data string) string {
+	parts := strings.Fields(data)
+	if len(parts) != len(soaFields) {
+		return data
+	}
+	fields := make(map[string]string, len(parts))
+	for i, key := range soaFields {
+		fields[key] = parts[i]
+	}
+	return fmt.Sprintf("serial=%s, refresh=%s, retry=%s, expire=%s, minttl=%s, ns=%s, email=%s",
+		fields["serial"], fields["refresh"], fields["retry"], fields["expire"], fields["minttl"], fields["ns"], fields["email"])
+}
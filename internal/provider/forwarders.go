@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// forwarderSettings are how a DNS server, or one of its conditional forwarder zones, forwards queries
+type forwarderSettings struct {
+	// Forwarders are tried in order
+	Forwarders []string
+	// Timeout is how long each forwarder gets to answer, in seconds
+	Timeout int
+	// ForwardersOnly stops the server from recursing itself when no forwarder answers
+	ForwardersOnly bool
+}
+
+// defaultForwardTimeout is the forwarder timeout Windows and Samba report when none is set
+const defaultForwardTimeout = 3
+
+// parseForwarderSettings reads forwarder settings from serverinfo or zoneinfo fields
+// Example serverinfo fields:
+//
+//	aipForwarders               : ['10.0.0.53', '10.0.1.53']
+//	dwForwardTimeout            : 3
+//	fSlave                      : FALSE
+//
+// A conditional forwarder zone reports them as aipMasters, dwForwarderTimeout and fForwarderSlave
+func parseForwarderSettings(info map[string]string, forwarders, timeout, only string) forwarderSettings {
+	s := forwarderSettings{
+		Forwarders:     parseAddressList(info[forwarders]),
+		Timeout:        defaultForwardTimeout,
+		ForwardersOnly: strings.EqualFold(info[only], "TRUE"),
+	}
+	if t, err := strconv.Atoi(info[timeout]); err == nil {
+		s.Timeout = t
+	}
+	return s
+}
+
+// parseAddressList parses an address list as samba-tool prints it, e.g. "['10.0.0.53', '10.0.1.53']"
+// A bare space or comma separated list is accepted too
+func parseAddressList(raw string) []string {
+	raw = strings.Trim(strings.TrimSpace(raw), "[]")
+	addrs := []string{}
+	for _, field := range strings.FieldsFunc(raw, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if addr := strings.Trim(field, `'"`); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
+// formatAddressList prints an address list the way samba-tool does
+func formatAddressList(addrs []string) string {
+	quoted := make([]string, len(addrs))
+	for i, addr := range addrs {
+		quoted[i] = "'" + addr + "'"
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// equal reports whether two settings forward the same way; order matters, since forwarders are tried in order
+func (s forwarderSettings) equal(o forwarderSettings) bool {
+	if s.Timeout != o.Timeout || s.ForwardersOnly != o.ForwardersOnly || len(s.Forwarders) != len(o.Forwarders) {
+		return false
+	}
+	for i := range s.Forwarders {
+		if s.Forwarders[i] != o.Forwarders[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ForwarderSettings reads the forwarder settings of a server, or of a conditional forwarder zone when zone is set
+// The settings are read fresh, so a refresh sees changes made on the DC
+func (c *SambaClient) ForwarderSettings(server, zone string) (*forwarderSettings, error) {
+	if zone == "" {
+		c.info.forget(serverInfoKey(server))
+		info, err := c.ServerInfo(server)
+		if err != nil {
+			return nil, err
+		}
+		s := parseForwarderSettings(info, "aipForwarders", "dwForwardTimeout", "fSlave")
+		return &s, nil
+	}
+
+	c.info.forget(zoneInfoKey(server, zone))
+	info, err := c.ZoneInfo(server, zone)
+	if err != nil {
+		return nil, err
+	}
+	if zoneType := info["dwZoneType"]; zoneType != "DNS_ZONE_TYPE_FORWARDER" {
+		return nil, fmt.Errorf("zone %s on %s is not a conditional forwarder zone (zoneinfo reports %s)", zone, server, zoneType)
+	}
+	s := parseForwarderSettings(info, "aipMasters", "dwForwarderTimeout", "fForwarderSlave")
+	return &s, nil
+}
+
+// errForwardersReadOnly is returned when the backend cannot write forwarder settings
+var errForwardersReadOnly = errors.New("forwarder settings cannot be written through samba-tool: it has no command for them, " +
+	"and the Samba internal DNS server reads its forwarders from the `dns forwarder` option in smb.conf")
+
+// forwarderWriter is implemented by runners that can change forwarder settings
+// samba-tool cannot, so only the file backend does
+type forwarderWriter interface {
+	writeForwarders(server, zone string, s forwarderSettings) error
+}
+
+// canWriteForwarders reports whether the client's backend can write forwarder settings
+func (c *SambaClient) canWriteForwarders() bool {
+	_, ok := c.runner.(forwarderWriter)
+	return ok
+}
+
+// SetForwarderSettings replaces the forwarder settings of a server, or of a conditional forwarder zone when zone is set
+func (c *SambaClient) SetForwarderSettings(server, zone string, s forwarderSettings) error {
+	writer, ok := c.runner.(forwarderWriter)
+	if !ok {
+		return errForwardersReadOnly
+	}
+	if err := writer.writeForwarders(server, zone, s); err != nil {
+		return err
+	}
+	// serverinfo and zoneinfo are cached for the provider run
+	c.info.forget(serverInfoKey(server))
+	if zone != "" {
+		c.info.forget(zoneInfoKey(server, zone))
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"strings"
+	"sync"
+)
+
+// infoCache keeps serverinfo, zonelist and zoneinfo output for the lifetime of the provider process
+// Placement checks, preflight and capability detection ask for the same fields once per
+// resource; concurrent requests for one key share a single samba-tool call.
+// Only successful results are kept. Clients cloned for per-resource credentials share the parent's cache.
+type infoCache struct {
+	mu      sync.Mutex
+	entries map[string]*infoEntry
+}
+
+type infoEntry struct {
+	ready  chan struct{}
+	fields map[string]string
+	err    error
+}
+
+func newInfoCache() *infoCache {
+	return &infoCache{entries: make(map[string]*infoEntry)}
+}
+
+// get returns the cached fields for key, calling fetch when there are none
+func (ic *infoCache) get(key string, fetch func() (map[string]string, error)) (map[string]string, error) {
+	if ic == nil {
+		return fetch()
+	}
+	key = strings.ToLower(key)
+
+	ic.mu.Lock()
+	if entry, ok := ic.entries[key]; ok {
+		ic.mu.Unlock()
+		<-entry.ready
+		if entry.err == nil {
+			return entry.fields, nil
+		}
+		// The call that failed has already dropped the entry; retry independently
+		return ic.get(key, fetch)
+	}
+	entry := &infoEntry{ready: make(chan struct{})}
+	ic.entries[key] = entry
+	ic.mu.Unlock()
+
+	entry.fields, entry.err = fetch()
+	if entry.err != nil {
+		ic.forget(key)
+	}
+	close(entry.ready)
+	return entry.fields, entry.err
+}
+
+// forget drops the cached fields for key
+func (ic *infoCache) forget(key string) {
+	if ic == nil {
+		return
+	}
+	ic.mu.Lock()
+	delete(ic.entries, strings.ToLower(key))
+	ic.mu.Unlock()
+}
+
+func serverInfoKey(server string) string {
+	return "serverinfo/" + server
+}
+
+func zoneListKey(server string) string {
+	return "zonelist/" + server
+}
+
+func zoneInfoKey(server, zone string) string {
+	return "zoneinfo/" + server + "/" + zone
+}
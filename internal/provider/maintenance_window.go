@@ -0,0 +1,284 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	// Embedded zone data keeps timezone working on hosts without /usr/share/zoneinfo
+	_ "time/tzdata"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// maintenanceWindowSchema returns the provider-level maintenance window block
+func maintenanceWindowSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Refuse to update or delete resources outside an approved window; the check runs before the first write. Creating resources is always allowed.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"schedule": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validateCronSchedule,
+					Description:  "Cron expression for the start of each window: minute, hour, day of month, month and day of week (e.g., `0 22 * * 6` for Saturdays at 22:00).",
+				},
+				"duration": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ValidateFunc: validateDuration,
+					Description:  "How long each window stays open (e.g., `4h`), at most a week.",
+				},
+				"timezone": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "UTC",
+					Description: "IANA time zone the schedule is evaluated in (e.g., `Europe/Berlin`).",
+				},
+				"override": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Allow destructive changes outside the window, for emergencies. Setting the SAMBADNS_MAINTENANCE_OVERRIDE env var to `true` does the same without editing the configuration.",
+				},
+			},
+		},
+	}
+}
+
+// maxWindowDuration bounds how far back a window start is searched for
+const maxWindowDuration = 7 * 24 * time.Hour
+
+// cronField is the set of values one cron field matches
+type cronField map[int]bool
+
+// cronSchedule is a parsed five-field cron expression
+type cronSchedule struct {
+	minute, hour, dom, month, dow cronField
+	// domAny and dowAny record a "*" day field; when both day fields are restricted, either may match
+	domAny, dowAny bool
+}
+
+// maintenanceWindow decides whether destructive changes are allowed now
+type maintenanceWindow struct {
+	spec     string
+	schedule cronSchedule
+	duration time.Duration
+	location *time.Location
+	override bool
+	now      func() time.Time
+}
+
+// newMaintenanceWindow builds a window from a maintenance_window block
+func newMaintenanceWindow(cfg map[string]interface{}, override bool) (*maintenanceWindow, error) {
+	spec := cfg["schedule"].(string)
+	schedule, err := parseCronSchedule(spec)
+	if err != nil {
+		return nil, fmt.Errorf("maintenance_window: %w", err)
+	}
+	duration, err := time.ParseDuration(cfg["duration"].(string))
+	if err != nil || duration <= 0 || duration > maxWindowDuration {
+		return nil, fmt.Errorf("maintenance_window: duration must be positive and at most %s", maxWindowDuration)
+	}
+	location, err := time.LoadLocation(cfg["timezone"].(string))
+	if err != nil {
+		return nil, fmt.Errorf("maintenance_window: %w", err)
+	}
+	return &maintenanceWindow{
+		spec:     spec,
+		schedule: schedule,
+		duration: duration,
+		location: location,
+		override: override || cfg["override"].(bool),
+		now:      time.Now,
+	}, nil
+}
+
+// allow returns an error naming the change when it is outside the window
+// Durations are elapsed time, so a window spanning a DST change stays open exactly as long
+func (w *maintenanceWindow) allow(change string) error {
+	if w == nil || w.override {
+		return nil
+	}
+	now := w.now().In(w.location).Truncate(time.Minute)
+	for t := now; now.Sub(t) < w.duration; t = t.Add(-time.Minute) {
+		if w.schedule.matches(t) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s is a destructive change and it is %s, outside the maintenance window (%q for %s, %s). "+
+		"Apply during the window, or set SAMBADNS_MAINTENANCE_OVERRIDE=true for an approved emergency change",
+		change, now.Format("Mon 15:04 MST"), w.spec, w.duration, w.location)
+}
+
+// check returns an error when a destructive command runs outside the window
+// Updates and deletes are checked before they start, see guardMaintenanceWindow; this catches the
+// destructive commands other operations run
+func (w *maintenanceWindow) check(args []string) error {
+	if w == nil || !isDestructiveCommand(args) {
+		return nil
+	}
+	return w.allow("samba-tool dns " + args[1])
+}
+
+// isDestructiveCommand reports whether samba-tool arguments remove or overwrite DNS data
+// Rewriting the SOA only moves the serial forward, see BumpSerial, which loses nothing
+func isDestructiveCommand(args []string) bool {
+	if len(args) < 2 || args[0] != "dns" {
+		return false
+	}
+	switch args[1] {
+	case "delete", "zonedelete":
+		return true
+	case "update":
+		return len(args) < 6 || !strings.EqualFold(args[5], "SOA")
+	}
+	return false
+}
+
+// windowClearedKey is the context key marking an operation that was checked against the window before it started
+type windowClearedKey struct{}
+
+// windowClearedFrom reports whether the operation in ctx was already checked against the window
+func windowClearedFrom(ctx context.Context) bool {
+	cleared, _ := ctx.Value(windowClearedKey{}).(bool)
+	return cleared
+}
+
+// withWindowCleared returns a copy of the client that skips the per-command window check when cleared is set
+func (c *SambaClient) withWindowCleared(cleared bool) *SambaClient {
+	if !cleared || c.window == nil {
+		return c
+	}
+	clone := *c
+	clone.window = nil
+	return &clone
+}
+
+// windowOf returns the maintenance window of the provider, nil when there is none
+func windowOf(m interface{}) *maintenanceWindow {
+	if a, ok := m.(*apiClient); ok && a.client != nil {
+		return a.client.window
+	}
+	return nil
+}
+
+// guardMaintenanceWindow checks a resource's updates and deletes against the window before they write anything
+// Checked per command instead, a rename outside the window would add the new name and then be refused
+// the delete of the old one, and the rollback deleting the new name again
+func guardMaintenanceWindow(name string, r *schema.Resource) {
+	guard := func(verb string, f crudFunc) crudFunc {
+		return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			if err := windowOf(m).allow(fmt.Sprintf("%s %s %s", verb, name, d.Id())); err != nil {
+				return diag.FromErr(err)
+			}
+			return f(context.WithValue(ctx, windowClearedKey{}, true), d, m)
+		}
+	}
+	if r.UpdateContext != nil {
+		r.UpdateContext = guard("updating", r.UpdateContext)
+	}
+	if r.DeleteContext != nil {
+		r.DeleteContext = guard("deleting", r.DeleteContext)
+	}
+}
+
+func (s cronSchedule) matches(t time.Time) bool {
+	if !s.minute[t.Minute()] || !s.hour[t.Hour()] || !s.month[int(t.Month())] {
+		return false
+	}
+	dom, dow := s.dom[t.Day()], s.dow[int(t.Weekday())]
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dow
+	case s.dowAny:
+		return dom
+	}
+	return dom || dow
+}
+
+// parseCronSchedule parses "minute hour day-of-month month day-of-week"
+// Fields take *, numbers, ranges (1-5), lists (1,3) and steps (*/15, 8-18/2); day of week 7 is Sunday
+func parseCronSchedule(spec string) (cronSchedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return cronSchedule{}, fmt.Errorf("schedule %q needs five fields: minute, hour, day of month, month and day of week", spec)
+	}
+
+	var s cronSchedule
+	var err error
+	for i, f := range []struct {
+		field    *cronField
+		min, max int
+		name     string
+	}{
+		{&s.minute, 0, 59, "minute"},
+		{&s.hour, 0, 23, "hour"},
+		{&s.dom, 1, 31, "day of month"},
+		{&s.month, 1, 12, "month"},
+		{&s.dow, 0, 7, "day of week"},
+	} {
+		if *f.field, err = parseCronField(fields[i], f.min, f.max); err != nil {
+			return cronSchedule{}, fmt.Errorf("schedule %q: %s: %w", spec, f.name, err)
+		}
+	}
+	if s.dow[7] {
+		s.dow[0] = true
+	}
+	s.domAny = fields[2] == "*"
+	s.dowAny = fields[4] == "*"
+	return s, nil
+}
+
+func parseCronField(field string, min, max int) (cronField, error) {
+	values := make(cronField)
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid step %q", part)
+			}
+			step = n
+		}
+
+		low, high := min, max
+		if rangePart != "*" {
+			lowText, highText, isRange := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = strconv.Atoi(lowText); err != nil {
+				return nil, fmt.Errorf("invalid value %q", part)
+			}
+			high = low
+			if isRange {
+				if high, err = strconv.Atoi(highText); err != nil {
+					return nil, fmt.Errorf("invalid value %q", part)
+				}
+			} else if hasStep {
+				high = max
+			}
+		}
+		if low < min || high > max || low > high {
+			return nil, fmt.Errorf("%q is outside %d-%d", part, min, max)
+		}
+		for v := low; v <= high; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+func validateCronSchedule(v interface{}, k string) (warnings []string, errs []error) {
+	if _, err := parseCronSchedule(v.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q: %w", k, err))
+	}
+	return warnings, errs
+}
@@ -0,0 +1,206 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestCronScheduleMatches(t *testing.T) {
+	cases := []struct {
+		spec string
+		at   string // local time in UTC, "2006-01-02 15:04"
+		want bool
+	}{
+		// Ranges and lists
+		{"0 9-17 * * *", "2026-10-14 09:00", true},
+		{"0 9-17 * * *", "2026-10-14 17:00", true},
+		{"0 9-17 * * *", "2026-10-14 18:00", false},
+		{"0,30 * * * *", "2026-10-14 10:30", true},
+		{"0,30 * * * *", "2026-10-14 10:15", false},
+		// Steps, over the whole field and over a range
+		{"*/15 * * * *", "2026-10-14 10:45", true},
+		{"*/15 * * * *", "2026-10-14 10:50", false},
+		{"0 8-18/2 * * *", "2026-10-14 14:00", true},
+		{"0 8-18/2 * * *", "2026-10-14 15:00", false},
+		{"5/20 * * * *", "2026-10-14 10:45", true},
+		{"5/20 * * * *", "2026-10-14 10:40", false},
+		// Day of week, with 7 as Sunday; 2026-10-18 is a Sunday
+		{"0 22 * * 6", "2026-10-17 22:00", true},
+		{"0 22 * * 6", "2026-10-18 22:00", false},
+		{"0 0 * * 7", "2026-10-18 00:00", true},
+		{"0 0 * * 1-5", "2026-10-18 00:00", false},
+		// Both day fields restricted: either one matches
+		{"0 0 1 * 1", "2026-10-01 00:00", true}, // the 1st, a Thursday
+		{"0 0 1 * 1", "2026-10-19 00:00", true}, // a Monday
+		{"0 0 1 * 1", "2026-10-20 00:00", false},
+		// Only one day field restricted: it alone decides
+		{"0 0 1 * *", "2026-10-19 00:00", false},
+		{"0 0 * * 1", "2026-10-01 00:00", false},
+		// Month
+		{"0 0 * 1-3 *", "2026-02-10 00:00", true},
+		{"0 0 * 1-3 *", "2026-10-10 00:00", false},
+	}
+	for _, tc := range cases {
+		s, err := parseCronSchedule(tc.spec)
+		if err != nil {
+			t.Fatalf("parseCronSchedule(%q): %v", tc.spec, err)
+		}
+		at, err := time.Parse("2006-01-02 15:04", tc.at)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := s.matches(at); got != tc.want {
+			t.Errorf("%q matches %s = %t, want %t", tc.spec, tc.at, got, tc.want)
+		}
+	}
+}
+
+func TestParseCronScheduleRejects(t *testing.T) {
+	for _, spec := range []string{
+		"0 22 * *",      // four fields
+		"60 * * * *",    // minute out of range
+		"0 24 * * *",    // hour out of range
+		"0 0 0 * *",     // day of month starts at 1
+		"0 0 * 13 *",    // month out of range
+		"0 0 * * 8",     // day of week out of range
+		"0 5-1 * * *",   // reversed range
+		"*/0 * * * *",   // zero step
+		"a * * * *",     // not a number
+		"0 0 * * 1-5/x", // bad step
+	} {
+		if _, err := parseCronSchedule(spec); err == nil {
+			t.Errorf("parseCronSchedule(%q) accepted an invalid schedule", spec)
+		}
+	}
+}
+
+func TestMaintenanceWindowAllow(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name     string
+		spec     string
+		duration time.Duration
+		location *time.Location
+		now      time.Time
+		open     bool
+	}{
+		{"inside", "0 22 * * 6", 4 * time.Hour, time.UTC, time.Date(2026, 10, 17, 23, 0, 0, 0, time.UTC), true},
+		{"at the start", "0 22 * * 6", 4 * time.Hour, time.UTC, time.Date(2026, 10, 17, 22, 0, 0, 0, time.UTC), true},
+		{"before the start", "0 22 * * 6", 4 * time.Hour, time.UTC, time.Date(2026, 10, 17, 21, 59, 0, 0, time.UTC), false},
+		{"past midnight", "0 22 * * 6", 4 * time.Hour, time.UTC, time.Date(2026, 10, 18, 1, 59, 0, 0, time.UTC), true},
+		{"closed past midnight", "0 22 * * 6", 4 * time.Hour, time.UTC, time.Date(2026, 10, 18, 2, 0, 0, 0, time.UTC), false},
+		{"in the time zone", "0 22 * * 6", time.Hour, berlin, time.Date(2026, 10, 17, 20, 30, 0, 0, time.UTC), true},
+		{"UTC is not the time zone", "0 22 * * 6", time.Hour, berlin, time.Date(2026, 10, 17, 22, 30, 0, 0, time.UTC), false},
+		// On 2026-03-29 Berlin clocks jump from 02:00 to 03:00, so 02:30 never happens and that day has no window
+		{"start in the DST gap", "30 2 * * *", 2 * time.Hour, berlin, time.Date(2026, 3, 29, 1, 15, 0, 0, time.UTC), false},
+		// A window opening at 01:30 CET lasts two elapsed hours, until 04:30 CEST on the clock
+		{"across the DST gap", "30 1 * * *", 2 * time.Hour, berlin, time.Date(2026, 3, 29, 2, 15, 0, 0, time.UTC), true},
+		{"closed after the DST gap", "30 1 * * *", 2 * time.Hour, berlin, time.Date(2026, 3, 29, 2, 30, 0, 0, time.UTC), false},
+		// On 2026-10-25 01:30 happens once and 02:30 twice, and the window opens at the first
+		{"repeated hour", "30 2 * * *", time.Hour, berlin, time.Date(2026, 10, 25, 1, 0, 0, 0, time.UTC), true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := parseCronSchedule(tc.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			now := tc.now
+			w := &maintenanceWindow{spec: tc.spec, schedule: s, duration: tc.duration, location: tc.location, now: func() time.Time { return now }}
+			if err := w.allow("deleting a record"); (err == nil) != tc.open {
+				t.Errorf("at %s: allow = %v, want open %t", now.In(tc.location).Format(time.RFC3339), err, tc.open)
+			}
+			w.override = true
+			if err := w.allow("deleting a record"); err != nil {
+				t.Errorf("override did not open the window: %v", err)
+			}
+		})
+	}
+}
+
+func TestIsDestructiveCommand(t *testing.T) {
+	cases := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"dns", "add", "dc", "example.com", "web", "A", "10.0.0.1"}, false},
+		{[]string{"dns", "delete", "dc", "example.com", "web", "A", "10.0.0.1"}, true},
+		{[]string{"dns", "update", "dc", "example.com", "web", "A", "10.0.0.1", "10.0.0.2"}, true},
+		{[]string{"dns", "update", "dc", "example.com", "@", "SOA", "old", "new"}, false},
+		{[]string{"dns", "zonedelete", "dc", "example.com"}, true},
+		{[]string{"dns", "query", "dc", "example.com", "web", "A"}, false},
+	}
+	for _, tc := range cases {
+		if got := isDestructiveCommand(tc.args); got != tc.want {
+			t.Errorf("isDestructiveCommand(%v) = %t, want %t", tc.args, got, tc.want)
+		}
+	}
+}
+
+// closedWindow returns a window that never opens at the current time
+func closedWindow(t *testing.T) *maintenanceWindow {
+	t.Helper()
+	s, err := parseCronSchedule("0 0 1 1 *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &maintenanceWindow{spec: "0 0 1 1 *", schedule: s, duration: time.Minute, location: time.UTC,
+		now: func() time.Time { return time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC) }}
+}
+
+// TestGuardMaintenanceWindow checks that updates and deletes are refused before they run, and that
+// an operation let through skips the per-command check
+func TestGuardMaintenanceWindow(t *testing.T) {
+	c := newFileTestClient(t)
+	c.window = closedWindow(t)
+	m := &apiClient{client: c}
+
+	var ran, cleared bool
+	r := &schema.Resource{
+		UpdateContext: func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			ran, cleared = true, windowClearedFrom(ctx)
+			return nil
+		},
+	}
+	guardMaintenanceWindow("sambadns_record", r)
+	d := r.TestResourceData()
+	d.SetId("dc/example.com/web/A")
+
+	if diags := r.UpdateContext(context.Background(), d, m); !diags.HasError() || ran {
+		t.Fatalf("update outside the window ran (diagnostics %v)", diags)
+	}
+	c.window.override = true
+	if diags := r.UpdateContext(context.Background(), d, m); diags.HasError() || !ran || !cleared {
+		t.Fatalf("update inside the window: ran %t, cleared %t, diagnostics %v", ran, cleared, diags)
+	}
+}
+
+// TestRollbackIgnoresWindow checks that a failed write outside the window is undone
+func TestRollbackIgnoresWindow(t *testing.T) {
+	c := newFileTestClient(t)
+	c.window = closedWindow(t)
+	added := DNSRecord{Server: "dc", Zone: "example.com", Name: "web", Type: "A", Value: "10.0.0.1"}
+
+	tx := newRecordTransaction(c)
+	if err := tx.create(added); err != nil {
+		t.Fatalf("adding a record outside the window: %v", err)
+	}
+	if err := c.DeleteRecord(added); err == nil {
+		t.Fatal("delete outside the window was not refused")
+	}
+	if err := tx.rollback(errors.New("later write failed")); !strings.Contains(err.Error(), "rolled back 1") {
+		t.Fatalf("rollback: %v", err)
+	}
+	if r, err := c.QueryRecord("dc", "example.com", "web", "A"); err != nil || r != nil {
+		t.Fatalf("rollback kept the record: %+v (%v)", r, err)
+	}
+}
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// manifestSchema returns the provider-level signed manifest block
+func manifestSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Write a signed manifest of every samba-tool write the run performs, as tamper-evident evidence that the applied DNS changes match the approved plan.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"path": {
+					Type:        schema.TypeString,
+					Required:    true,
+					Description: "File the manifest is written to. It is created, replacing any earlier file, on the first write of a run; runs that write nothing, such as plans, leave it untouched.",
+				},
+				"hmac_key": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Sensitive:   true,
+					DefaultFunc: schema.EnvDefaultFunc("SAMBADNS_MANIFEST_HMAC_KEY", nil),
+					Description: "Secret to sign the manifest with HMAC-SHA256. Can also be set via SAMBADNS_MANIFEST_HMAC_KEY env var.",
+				},
+				"signing_key_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "PEM file holding a PKCS #8 Ed25519 private key to sign the manifest with instead of an HMAC secret.",
+				},
+			},
+		},
+	}
+}
+
+// manifestEntry is one samba-tool write in the manifest
+type manifestEntry struct {
+	Time     string   `json:"time"`
+	Identity string   `json:"identity"`
+	Command  []string `json:"command"`
+}
+
+// manifestBody is the signed part of the manifest
+type manifestBody struct {
+	Started   string          `json:"started"`
+	Version   string          `json:"provider_version"`
+	Mutations []manifestEntry `json:"mutations"`
+}
+
+type manifestSignature struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+}
+
+// mutationManifest records the writes of a provider run and keeps a signed copy on disk
+// The file is first written with the run's first write and rewritten after every later one, so it is
+// complete and signed whenever Terraform stops, and runs that write nothing keep the last run's evidence.
+// Clients cloned for per-resource credentials share the parent's manifest
+type mutationManifest struct {
+	path string
+	sign func(body []byte) manifestSignature
+
+	mu   sync.Mutex
+	body manifestBody
+}
+
+// newMutationManifest starts an empty manifest from a manifest block; nothing is written until record
+func newMutationManifest(cfg map[string]interface{}, version string) (*mutationManifest, error) {
+	hmacKey := cfg["hmac_key"].(string)
+	keyFile := cfg["signing_key_file"].(string)
+
+	m := &mutationManifest{
+		path: cfg["path"].(string),
+		body: manifestBody{
+			Started:   time.Now().UTC().Format(time.RFC3339),
+			Version:   version,
+			Mutations: []manifestEntry{},
+		},
+	}
+
+	switch {
+	case hmacKey != "" && keyFile != "":
+		return nil, fmt.Errorf("manifest: set either hmac_key or signing_key_file, not both")
+	case hmacKey != "":
+		m.sign = func(body []byte) manifestSignature {
+			mac := hmac.New(sha256.New, []byte(hmacKey))
+			mac.Write(body)
+			return manifestSignature{Algorithm: "HMAC-SHA256", Value: hex.EncodeToString(mac.Sum(nil))}
+		}
+	case keyFile != "":
+		key, err := readEd25519Key(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("manifest: %w", err)
+		}
+		m.sign = func(body []byte) manifestSignature {
+			return manifestSignature{Algorithm: "Ed25519", Value: base64.StdEncoding.EncodeToString(ed25519.Sign(key, body))}
+		}
+	default:
+		return nil, fmt.Errorf("manifest: hmac_key or signing_key_file is required to sign the manifest")
+	}
+	return m, nil
+}
+
+// readEd25519Key loads a PKCS #8 Ed25519 private key from a PEM file
+func readEd25519Key(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s holds no PEM block", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s holds a %T, not an Ed25519 private key", path, parsed)
+	}
+	return key, nil
+}
+
+// record adds a successful write to the manifest
+func (m *mutationManifest) record(identity string, args []string) error {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.body.Mutations = append(m.body.Mutations, manifestEntry{
+		Time:     time.Now().UTC().Format(time.RFC3339Nano),
+		Identity: identity,
+		Command:  append([]string{"samba-tool"}, args...),
+	})
+	return m.write()
+}
+
+// write signs the compact JSON of the body and saves it with the signature
+// Verifiers recompute the signature over the body as `jq -cj 'del(.signature)'` prints it
+func (m *mutationManifest) write() error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(m.body); err != nil {
+		return err
+	}
+	signed := bytes.TrimSuffix(body.Bytes(), []byte("\n"))
+
+	doc := struct {
+		manifestBody
+		Signature manifestSignature `json:"signature"`
+	}{m.body, m.sign(signed)}
+
+	var out bytes.Buffer
+	enc = json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if err := os.WriteFile(m.path, out.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
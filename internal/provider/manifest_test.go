@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// signedBody rebuilds the signed bytes of a manifest file the way the documentation tells verifiers to,
+// `jq -cj 'del(.signature)'`: the top-level fields in file order without signature, as compact JSON
+func signedBody(t *testing.T, data []byte) ([]byte, manifestSignature) {
+	t.Helper()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		t.Fatalf("manifest is not a JSON object: %v", err)
+	}
+	var body bytes.Buffer
+	var sig manifestSignature
+	body.WriteByte('{')
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			t.Fatal(err)
+		}
+		if key == "signature" {
+			if err := json.Unmarshal(value, &sig); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if body.Len() > 1 {
+			body.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		body.Write(name)
+		body.WriteByte(':')
+		if err := json.Compact(&body, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	body.WriteByte('}')
+	return body.Bytes(), sig
+}
+
+// recordWrites records two writes, one with characters JSON encoders disagree on
+func recordWrites(t *testing.T, m *mutationManifest) {
+	t.Helper()
+	for _, args := range [][]string{
+		{"dns", "add", "dc", "example.com", "web", "A", "10.0.0.1"},
+		{"dns", "add", "dc", "example.com", "spf", "TXT", `"v=spf1 <a> & -all"`},
+	} {
+		if err := m.record("admin@EXAMPLE.COM", args); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestManifestHMACSignature(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	m, err := newMutationManifest(map[string]interface{}{"path": path, "hmac_key": "secret", "signing_key_file": ""}, "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordWrites(t, m)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, sig := signedBody(t, data)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(body)
+	if sig.Algorithm != "HMAC-SHA256" || sig.Value != hex.EncodeToString(mac.Sum(nil)) {
+		t.Fatalf("signature %+v does not verify over %s", sig, body)
+	}
+
+	var parsed manifestBody
+	if err := json.Unmarshal(body, &parsed); err != nil || len(parsed.Mutations) != 2 || parsed.Version != "1.2.3" {
+		t.Fatalf("signed body %s: %v", body, err)
+	}
+}
+
+func TestManifestEd25519Signature(t *testing.T) {
+	dir := t.TempDir()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "manifest.json")
+	m, err := newMutationManifest(map[string]interface{}{"path": path, "hmac_key": "", "signing_key_file": keyFile}, "1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recordWrites(t, m)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, sig := signedBody(t, data)
+	value, err := base64.StdEncoding.DecodeString(sig.Value)
+	if err != nil || sig.Algorithm != "Ed25519" || !ed25519.Verify(public, body, value) {
+		t.Fatalf("signature %+v does not verify over %s (%v)", sig, body, err)
+	}
+}
+
+// TestManifestKeepsEvidence checks that a run without writes leaves the previous manifest alone
+func TestManifestKeepsEvidence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	evidence := []byte(`{"mutations":["from the last apply"]}`)
+	if err := os.WriteFile(path, evidence, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newMutationManifest(map[string]interface{}{"path": path, "hmac_key": "secret", "signing_key_file": ""}, "1.2.3"); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(path); err != nil || !bytes.Equal(data, evidence) {
+		t.Fatalf("configuring the provider rewrote the manifest: %s (%v)", data, err)
+	}
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// writeMetadataSchema returns the computed block recording which provider last wrote a resource
+func writeMetadataSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Computed:    true,
+		Description: "Provider version, backend and time of the last successful write, for attributing DNS changes to Terraform runs.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"provider_version": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Version of the provider that performed the last write.",
+				},
+				"backend": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "Backend the write went through (e.g., `samba-tool` or `docker:samba-dc`).",
+				},
+				"last_write": {
+					Type:        schema.TypeString,
+					Computed:    true,
+					Description: "RFC 3339 UTC timestamp of the last successful create or update.",
+				},
+			},
+		},
+	}
+}
+
+// setWriteMetadata stamps the resource after a successful create or update
+// Reads leave the block untouched, so it reflects the last write rather than the last refresh
+func setWriteMetadata(d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	a := m.(*apiClient)
+	state := newStateSetter(d)
+	state.set("write_metadata", []interface{}{
+		map[string]interface{}{
+			"provider_version": a.version,
+			"backend":          a.backend,
+			"last_write":       time.Now().UTC().Format(time.RFC3339),
+		},
+	})
+	return state.diags
+}
+
+// backendName describes how samba-tool is reached, for write metadata
+func backendName(container map[string]interface{}) string {
+	if container == nil {
+		return "samba-tool"
+	}
+	return container["runtime"].(string) + ":" + container["name"].(string)
+}
@@ -0,0 +1,306 @@
+package provider
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"unicode/utf8"
+)
+
+// Value normalization shared by create idempotency, read mapping, diff suppression and delete.
+// Keeping the rules in one place means a value that suppresses a diff is also accepted as
+// already present on create, and is deleted in the form samba-tool stored it.
+
+// normalizeIPv6 expands an IPv6 address to its full form for comparison
+// e.g., "2001:db8::1" -> "2001:0db8:0000:0000:0000:0000:0000:0001"
+// Uppercase hex, leading zeros in groups and IPv4-mapped forms all expand alike, so
+// "::FFFF:10.0.0.1", "::ffff:a00:1" and "0:0:0:0:0:ffff:0a00:0001" compare equal
+func normalizeIPv6(ip string) string {
+	ip = strings.TrimSpace(ip)
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return strings.ToLower(ip) // Not a valid IP, compare case-insensitively
+	}
+	// IPv4-mapped addresses parse to the same value as plain IPv4, so the notation decides
+	if !strings.Contains(ip, ":") {
+		return ip // It's IPv4, return as-is
+	}
+	// Expand to full IPv6 format
+	ipv6 := parsed.To16()
+	if ipv6 == nil {
+		return ip
+	}
+	// Format as 8 groups of 4 hex digits
+	return fmt.Sprintf("%02x%02x:%02x%02x:%02x%02x:%02x%02x:%02x%02x:%02x%02x:%02x%02x:%02x%02x",
+		ipv6[0], ipv6[1], ipv6[2], ipv6[3],
+		ipv6[4], ipv6[5], ipv6[6], ipv6[7],
+		ipv6[8], ipv6[9], ipv6[10], ipv6[11],
+		ipv6[12], ipv6[13], ipv6[14], ipv6[15])
+}
+
+// normalizeHostname lowercases a hostname, spells its escapes canonically and drops its trailing dot
+func normalizeHostname(host string) string {
+	return strings.ToLower(canonicalName(host))
+}
+
+// canonicalValue returns the comparison form of a record value
+// - AAAA: expanded IPv6
+// - CNAME, NS, PTR: lowercase hostname without trailing dot
+// - MX, SRV: normalized host followed by its numbers, single-spaced
+// - TXT: the strings it stands for, spelled as samba-tool dns query prints them
+// Other types compare verbatim
+func canonicalValue(recordType, value string) string {
+	switch strings.ToUpper(recordType) {
+	case "AAAA":
+		return normalizeIPv6(value)
+	case "CNAME", "NS", "PTR":
+		return normalizeHostname(value)
+	case "MX", "SRV":
+		host, rest, _ := strings.Cut(strings.TrimSpace(value), " ")
+		return strings.Join(append([]string{normalizeHostname(host)}, strings.Fields(rest)...), " ")
+	case "TXT":
+		return canonicalTXT(value)
+	default:
+		return value
+	}
+}
+
+// TXT values are UTF-8 text. A backslash followed by three decimal digits is a byte, as in RFC 1035
+// zone files, so bytes that are not UTF-8 or not printable can be written as \DDD. Values are handed to
+// samba-tool as raw bytes, and canonical values spell every byte raw except quotes, backslashes,
+// control characters and invalid UTF-8, which are escaped; values read back compare equal either way
+
+// canonicalTXT spells a TXT value the way samba-tool dns query prints it: each string double-quoted,
+// separated by commas, so "foo bar", 'foo bar' and foo\ bar all compare as "foo bar"
+// A value that cannot be split, e.g. with an unterminated quote, is kept verbatim
+func canonicalTXT(value string) string {
+	strs, err := txtStrings(value)
+	if err != nil {
+		return value
+	}
+	quoted := make([]string, len(strs))
+	for i, s := range strs {
+		quoted[i] = `"` + escapeTXTString(s) + `"`
+	}
+	return strings.Join(quoted, ",")
+}
+
+// escapeTXTString escapes the bytes of a TXT string that cannot appear raw inside double quotes
+func escapeTXTString(s string) string {
+	var b strings.Builder
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(s[0])
+		case r == utf8.RuneError && size <= 1, r < ' ', r == 0x7f:
+			fmt.Fprintf(&b, "\\%03d", s[0])
+		default:
+			b.WriteString(s[:size])
+		}
+		s = s[size:]
+	}
+	return b.String()
+}
+
+// escapeInvalidUTF8 writes the bytes of s that are not UTF-8 as \DDD escapes
+// samba-tool prints stored bytes as they are, and state only holds UTF-8 strings
+func escapeInvalidUTF8(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	var b strings.Builder
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		if r == utf8.RuneError && size <= 1 {
+			fmt.Fprintf(&b, "\\%03d", s[0])
+		} else {
+			b.WriteString(s[:size])
+		}
+		s = s[size:]
+	}
+	return b.String()
+}
+
+// txtEscape reads the escape at s[i], a backslash, returning the byte it stands for and its length
+// \DDD is a decimal byte; a backslash before any other character makes that character literal
+func txtEscape(s string, i int) (byte, int) {
+	if i+3 < len(s) && isDigit(s[i+1]) && isDigit(s[i+2]) && isDigit(s[i+3]) {
+		if v := int(s[i+1]-'0')*100 + int(s[i+2]-'0')*10 + int(s[i+3]-'0'); v <= 255 {
+			return byte(v), 4
+		}
+	}
+	return s[i+1], 2
+}
+
+// txtStrings returns the strings of a TXT value
+// Query output, a list of double-quoted strings separated by commas, is read as printed. Any other
+// value is split the way samba-tool dns add splits its argument, with shell quoting rules: whitespace
+// separates strings, single quotes are literal, and a backslash escapes the next character outside
+// single quotes (inside double quotes only before " and \). \DDD escapes are bytes except in single quotes
+func txtStrings(value string) ([]string, error) {
+	if strs, ok := quotedTXTList(value); ok {
+		return strs, nil
+	}
+
+	var strs []string
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				strs = append(strs, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\'':
+			end := strings.IndexByte(value[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote in %q", value)
+			}
+			word.WriteString(value[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '"':
+			j := i + 1
+			for ; j < len(value) && value[j] != '"'; j++ {
+				if value[j] == '\\' && j+1 < len(value) {
+					if escaped, n := txtEscape(value, j); n == 4 || escaped == '"' || escaped == '\\' {
+						word.WriteByte(escaped)
+						j += n - 1
+						continue
+					}
+				}
+				word.WriteByte(value[j])
+			}
+			if j >= len(value) {
+				return nil, fmt.Errorf("unterminated double quote in %q", value)
+			}
+			i = j
+			inWord = true
+		case c == '\\' && i+1 < len(value):
+			escaped, n := txtEscape(value, i)
+			word.WriteByte(escaped)
+			i += n - 1
+			inWord = true
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		strs = append(strs, word.String())
+	}
+	return strs, nil
+}
+
+// quotedTXTList reads a value in query output form, double-quoted strings separated by commas or spaces
+// ok is false for any other value
+func quotedTXTList(value string) (strs []string, ok bool) {
+	tokens, err := tokenizeRecordValue(value)
+	if err != nil || len(tokens) == 0 {
+		return nil, false
+	}
+	for i, t := range tokens {
+		// Quoted strings run together (e.g. "a"b) are one shell word, not a list
+		if t.kind != tokenQuoted || (i > 0 && t.start == tokens[i-1].end) {
+			return nil, false
+		}
+	}
+	strs = make([]string, len(tokens))
+	for i, t := range tokens {
+		raw := value[t.start+1 : t.end-1]
+		var b strings.Builder
+		for j := 0; j < len(raw); j++ {
+			if raw[j] == '\\' && j+1 < len(raw) {
+				c, n := txtEscape(raw, j)
+				b.WriteByte(c)
+				j += n - 1
+				continue
+			}
+			b.WriteByte(raw[j])
+		}
+		strs[i] = b.String()
+	}
+	return strs, true
+}
+
+// txtArgument is a TXT value as the argument of samba-tool dns add or delete
+// Its strings are quoted for samba-tool's shell-style split, with \DDD escapes turned into raw bytes,
+// so samba-tool stores exactly the strings the provider compares. Query output ("s1","s2") becomes 's1' 's2'
+func txtArgument(value string) string {
+	strs, err := txtStrings(value)
+	if err != nil {
+		return value
+	}
+	args := make([]string, len(strs))
+	for i, s := range strs {
+		args[i] = shellQuote(s)
+	}
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes one string for samba-tool's shell-style split of a TXT argument
+func shellQuote(s string) string {
+	if !strings.Contains(s, "'") {
+		return "'" + s + "'"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// maxTXTString is the length limit of one DNS character-string, in bytes
+const maxTXTString = 255
+
+// validateTXTBytes rejects TXT values samba-tool cannot be given: arguments cannot hold NUL bytes,
+// and each string of the record is a DNS character-string of at most 255 bytes
+func validateTXTBytes(value string) error {
+	strs, err := txtStrings(value)
+	if err != nil {
+		return err
+	}
+	for _, s := range strs {
+		if strings.IndexByte(s, 0) >= 0 {
+			return fmt.Errorf("TXT value %q contains a NUL byte (\\000), which cannot be passed to samba-tool", value)
+		}
+		if len(s) > maxTXTString {
+			return fmt.Errorf("TXT string %.20q... is %d bytes long, over the %d-byte limit of a DNS character-string; split it into several quoted strings",
+				s, len(s), maxTXTString)
+		}
+	}
+	return nil
+}
+
+// recordValuesEqual compares two values of a record type, ignoring representation differences
+func recordValuesEqual(recordType, a, b string) bool {
+	return canonicalValue(recordType, a) == canonicalValue(recordType, b)
+}
+
+// deleteValue converts a stored value to the form samba-tool dns delete expects
+func deleteValue(recordType, value string) string {
+	// TXT records need special formatting for delete
+	if strings.ToUpper(recordType) == "TXT" {
+		return formatTXTForDelete(value)
+	}
+	return value
+}
+
+// formatTXTForDelete converts TXT value from query format to delete format
+// Query returns: "string1","string2"
+// Delete needs:  'string1' 'string2'
+// Commas inside the quoted strings are kept
+func formatTXTForDelete(value string) string {
+	if _, ok := quotedTXTList(value); ok || !strings.Contains(value, ",") {
+		return txtArgument(value)
+	}
+
+	// Not a list of quoted strings, split on "," as samba-tool printed it
+	var result []string
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		part = strings.Trim(part, "\"")
+		result = append(result, shellQuote(part))
+	}
+	return strings.Join(result, " ")
+}
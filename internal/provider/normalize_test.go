@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestTXTRoundTrip checks that a TXT value reaches samba-tool as the strings the provider compares,
+// and that the value samba-tool prints back compares equal to the configured one
+func TestTXTRoundTrip(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"plain", `v=spf1 -all`, []string{"v=spf1", "-all"}},
+		{"double quoted", `"v=spf1 -all"`, []string{"v=spf1 -all"}},
+		{"single quoted", `'v=spf1 -all'`, []string{"v=spf1 -all"}},
+		{"apostrophe and quotes", `"it's \"x\""`, []string{`it's "x"`}},
+		{"backslash", `"a\\b"`, []string{`a\b`}},
+		{"backslash and apostrophe", `"it's a\\b"`, []string{`it's a\b`}},
+		{"decimal escape", `"\065BC"`, []string{"ABC"}},
+		{"escaped quote byte", `"say \034hi\034"`, []string{`say "hi"`}},
+		{"invalid UTF-8", `"\128"`, []string{"\x80"}},
+		{"non-ASCII UTF-8", `"héllo ✓"`, []string{"héllo ✓"}},
+		{"several strings", `"first" "second's"`, []string{"first", "second's"}},
+		{"query output", `"a b","c"`, []string{"a b", "c"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			strs, err := txtStrings(tc.value)
+			if err != nil {
+				t.Fatalf("txtStrings(%q): %v", tc.value, err)
+			}
+			if !reflect.DeepEqual(strs, tc.want) {
+				t.Fatalf("txtStrings(%q) = %q, want %q", tc.value, strs, tc.want)
+			}
+
+			// samba-tool splits the argument with shell rules, which txtStrings applies to unlisted values
+			arg := txtArgument(tc.value)
+			sent, err := txtStrings(arg)
+			if err != nil {
+				t.Fatalf("txtStrings(txtArgument(%q) = %q): %v", tc.value, arg, err)
+			}
+			if !reflect.DeepEqual(sent, tc.want) {
+				t.Fatalf("txtArgument(%q) = %q, which samba-tool splits into %q, want %q", tc.value, arg, sent, tc.want)
+			}
+
+			// samba-tool prints the stored strings double-quoted and comma separated
+			printed := canonicalTXT(arg)
+			if !recordValuesEqual("TXT", printed, tc.value) {
+				t.Fatalf("printed value %q does not compare equal to %q", printed, tc.value)
+			}
+			read, err := txtStrings(printed)
+			if err != nil || !reflect.DeepEqual(read, tc.want) {
+				t.Fatalf("txtStrings(%q) = %q, %v, want %q", printed, read, err, tc.want)
+			}
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	cases := map[string]string{
+		`plain`:    `'plain'`,
+		`it's`:     `"it's"`,
+		`it's "x"`: `"it's \"x\""`,
+		`it's a\b`: `"it's a\\b"`,
+	}
+	for in, want := range cases {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
+
+func TestValidateTXTBytes(t *testing.T) {
+	long := strings.Repeat("a", maxTXTString)
+	cases := map[string]bool{
+		`"v=spf1 -all"`:                 true,
+		`"` + long + `"`:                true,
+		`"` + long + `","` + long + `"`: true,
+		`"` + long + `a"`:               false,
+		`"a\000b"`:                      false,
+	}
+	for value, valid := range cases {
+		if err := validateTXTBytes(value); (err == nil) != valid {
+			t.Errorf("validateTXTBytes(%.40q...) = %v, want valid %t", value, err, valid)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// operationOrderSchema returns the provider-level operation_order block
+func operationOrderSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: "Order the operations of an apply so creates and updates run before deletes, minimizing the time names stop resolving during large reshuffles.",
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"deletes_last": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     true,
+					Description: "Hold deletes until no create or update is running and none has started for `settle`.",
+				},
+				"settle": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "2s",
+					ValidateFunc: validateDuration,
+					Description:  "How long the apply must be free of creates and updates before held deletes run.",
+				},
+				"max_delay": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "10m",
+					ValidateFunc: validateDuration,
+					Description:  "Longest a delete is held; after that it runs regardless.",
+				},
+			},
+		},
+	}
+}
+
+// operationQueue holds deletes back while creates and updates are running
+// Terraform starts every operation whose dependencies are met at once, so the deletes of
+// names leaving a zone race the creates of the names replacing them. A delete waits until
+// no create or update has been running for the settle time; creates never wait, so an
+// operation Terraform ordered after a delete cannot hold that delete up
+type operationQueue struct {
+	settle   time.Duration
+	maxDelay time.Duration
+
+	mu     sync.Mutex
+	active int
+	// quietSince is when the last create or update started or finished, or the run began
+	quietSince time.Time
+	// changed is closed and replaced whenever active or quietSince changes
+	changed chan struct{}
+}
+
+// newOperationQueue builds a queue from an operation_order block, nil when deletes are not held
+func newOperationQueue(raw []interface{}) *operationQueue {
+	if len(raw) == 0 || raw[0] == nil {
+		return nil
+	}
+	cfg := raw[0].(map[string]interface{})
+	if !cfg["deletes_last"].(bool) {
+		return nil
+	}
+	// Validated by the schema
+	settle, _ := time.ParseDuration(cfg["settle"].(string))
+	maxDelay, _ := time.ParseDuration(cfg["max_delay"].(string))
+	return &operationQueue{
+		settle:     settle,
+		maxDelay:   maxDelay,
+		quietSince: time.Now(),
+		changed:    make(chan struct{}),
+	}
+}
+
+func (q *operationQueue) update(delta int) {
+	if q == nil {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.active += delta
+	q.quietSince = time.Now()
+	close(q.changed)
+	q.changed = make(chan struct{})
+}
+
+// beginWrite and endWrite bracket a create or update
+func (q *operationQueue) beginWrite() { q.update(1) }
+func (q *operationQueue) endWrite()   { q.update(-1) }
+
+// waitForWrites blocks a delete until creates and updates have settled, or max_delay has passed
+func (q *operationQueue) waitForWrites(ctx context.Context, id string) error {
+	if q == nil {
+		return nil
+	}
+	start := time.Now()
+	logged := false
+	for {
+		q.mu.Lock()
+		active, quiet, changed := q.active, time.Since(q.quietSince), q.changed
+		q.mu.Unlock()
+
+		if active == 0 && quiet >= q.settle {
+			if logged {
+				log.Printf("[INFO] delete of %s ran after waiting %s for creates and updates", id, time.Since(start).Round(100*time.Millisecond))
+			}
+			return nil
+		}
+		held := time.Since(start)
+		if held >= q.maxDelay {
+			log.Printf("[WARN] delete of %s held for %s with %d creates or updates still running; deleting anyway", id, held.Round(time.Second), active)
+			return nil
+		}
+		if !logged {
+			log.Printf("[INFO] holding delete of %s until creates and updates settle (%d running)", id, active)
+			logged = true
+		}
+
+		wait := q.maxDelay - held
+		if active == 0 && q.settle-quiet < wait {
+			wait = q.settle - quiet
+		}
+		select {
+		case <-changed:
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// queueOf returns the provider's operation queue, nil when none is configured
+func queueOf(m interface{}) *operationQueue {
+	if a, ok := m.(*apiClient); ok {
+		return a.queue
+	}
+	return nil
+}
+
+// orderOperations makes a resource's creates and updates visible to the operation queue and its deletes wait on it
+func orderOperations(r *schema.Resource) {
+	create, update, del := r.CreateContext, r.UpdateContext, r.DeleteContext
+	if create != nil {
+		r.CreateContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			q := queueOf(m)
+			q.beginWrite()
+			defer q.endWrite()
+			return create(ctx, d, m)
+		}
+	}
+	if update != nil {
+		r.UpdateContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			q := queueOf(m)
+			q.beginWrite()
+			defer q.endWrite()
+			return update(ctx, d, m)
+		}
+	}
+	if del != nil {
+		r.DeleteContext = func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			if err := queueOf(m).waitForWrites(ctx, d.Id()); err != nil {
+				return diag.FromErr(err)
+			}
+			return del(ctx, d, m)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package provider
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// latencyStats tracks how long samba-tool invocations take in this provider process
+// Clients cloned for per-resource credentials share the parent's stats
+type latencyStats struct {
+	mu    sync.Mutex
+	total time.Duration
+	count int
+}
+
+func (l *latencyStats) record(d time.Duration) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.total += d
+	l.count++
+}
+
+// mean returns the average invocation time, and false before the first invocation
+func (l *latencyStats) mean() (time.Duration, bool) {
+	if l == nil {
+		return 0, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.count == 0 {
+		return 0, false
+	}
+	return l.total / time.Duration(l.count), true
+}
+
+// planEstimateSchema returns the computed plan estimate attributes of bulk resources
+func planEstimateSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"planned_operations": {
+			Type:        schema.TypeInt,
+			Computed:    true,
+			Description: "Number of samba-tool writes the last planned change performs.",
+		},
+		"estimated_duration": {
+			Type:        schema.TypeString,
+			Computed:    true,
+			Description: "Estimate of how long the last planned change takes, from the samba-tool latency observed while planning. `unknown` when nothing was measured.",
+		},
+	}
+}
+
+// withPlanEstimate adds the plan estimate attributes to a resource schema
+func withPlanEstimate(s map[string]*schema.Schema) map[string]*schema.Schema {
+	for k, v := range planEstimateSchema() {
+		s[k] = v
+	}
+	return s
+}
+
+// estimateOperations returns a CustomizeDiff that shows count(d) backend writes and their ETA in the plan
+// Nothing is set when the plan makes no writes, so an unchanged resource stays without diff
+func estimateOperations(count func(d *schema.ResourceDiff) (int, bool)) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		ops, known := count(d)
+		if !known {
+			if err := d.SetNewComputed("planned_operations"); err != nil {
+				return err
+			}
+			return d.SetNewComputed("estimated_duration")
+		}
+		if ops == 0 {
+			return nil
+		}
+
+		eta := "unknown"
+		if m != nil {
+			if mean, ok := m.(*apiClient).client.latency.mean(); ok {
+				eta = (time.Duration(ops) * mean).Round(time.Second).String()
+			}
+		}
+
+		if err := d.SetNew("planned_operations", ops); err != nil {
+			return err
+		}
+		return d.SetNew("estimated_duration", eta)
+	}
+}
+
+// setChangeCount counts the elements added to and removed from a set attribute
+func setChangeCount(d *schema.ResourceDiff, key string) (int, bool) {
+	if !d.NewValueKnown(key) {
+		return 0, false
+	}
+	oldRaw, newRaw := d.GetChange(key)
+	oldSet, newSet := oldRaw.(*schema.Set), newRaw.(*schema.Set)
+	return newSet.Difference(oldSet).Len() + oldSet.Difference(newSet).Len(), true
+}
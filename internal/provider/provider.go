@@ -2,10 +2,16 @@ package provider
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 func init() {
@@ -20,26 +26,179 @@ func New(version string) func() *schema.Provider {
 			Schema: map[string]*schema.Schema{
 				"username": {
 					Type:        schema.TypeString,
-					Required:    true,
+					Optional:    true,
 					DefaultFunc: schema.EnvDefaultFunc("SAMBADNS_USERNAME", nil),
-					Description: "Username for samba-tool authentication (e.g., terraform@domain.com). Can also be set via SAMBADNS_USERNAME env var.",
+					Description: "Username for samba-tool authentication (e.g., terraform@domain.com). Can also be set via SAMBADNS_USERNAME env var. Required unless `backend = \"file\"`.",
 				},
 				"password": {
 					Type:        schema.TypeString,
-					Required:    true,
+					Optional:    true,
 					Sensitive:   true,
 					DefaultFunc: schema.EnvDefaultFunc("SAMBADNS_PASSWORD", nil),
-					Description: "Password for samba-tool authentication. Can also be set via SAMBADNS_PASSWORD env var.",
+					Description: "Password for samba-tool authentication. Can also be set via SAMBADNS_PASSWORD env var. Required unless `backend = \"file\"`.",
 				},
+				"backend": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "samba-tool",
+					ValidateFunc: validation.StringInSlice([]string{"samba-tool", "file"}, false),
+					Description: "`samba-tool` talks to a DC. `file` keeps zones and records in the local JSON file `backend_file` instead, " +
+						"for plan previews and CI validation without connectivity to a DC.",
+				},
+				"backend_file": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Path of the JSON file the `file` backend reads and writes. Created on the first write.",
+				},
+				"allowed_cidrs": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.IsCIDR},
+					Description: "Address ranges A/AAAA record values must fall within. Plans with values outside every range fail. Resources can override this with their own `allowed_cidrs`.",
+				},
+				"name_policy_regex": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validation.StringIsValidRegExp,
+					Description:  "Regular expression every record name must match, evaluated at plan time (e.g., `^(dev|stg|prd)-`).",
+				},
+				"name_denylist": {
+					Type:        schema.TypeSet,
+					Optional:    true,
+					Elem:        &schema.Schema{Type: schema.TypeString},
+					Description: "Record names that may not be managed, compared case-insensitively (e.g., `@`, `www`).",
+				},
+				"owner_id": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Description: "Opt-in ownership tracking. When set, every managed record gets a companion TXT record naming this owner, similar to external-dns heritage records.",
+				},
+				"owner_record_prefix": {
+					Type:        schema.TypeString,
+					Optional:    true,
+					Default:     "_sambadns-owner",
+					Description: "Label prepended to a record name to form its ownership TXT record name.",
+				},
+				"container": {
+					Type:        schema.TypeList,
+					Optional:    true,
+					MaxItems:    1,
+					Description: "Run samba-tool inside a container via `docker exec`, `podman exec` or `kubectl exec` instead of from the host PATH.",
+					Elem: &schema.Resource{
+						Schema: map[string]*schema.Schema{
+							"runtime": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								Default:      "docker",
+								ValidateFunc: validation.StringInSlice([]string{"docker", "podman", "kubectl"}, false),
+								Description:  "Container runtime CLI (docker, podman, kubectl).",
+							},
+							"name": {
+								Type:        schema.TypeString,
+								Required:    true,
+								Description: "Container name, or pod name for kubectl.",
+							},
+							"namespace": {
+								Type:        schema.TypeString,
+								Optional:    true,
+								Description: "Kubernetes namespace of the pod (kubectl only).",
+							},
+							"container": {
+								Type:        schema.TypeString,
+								Optional:    true,
+								Description: "Container within the pod (kubectl only). Defaults to the pod's default container.",
+							},
+						},
+					},
+				},
+				"read_batch_window": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ValidateFunc: validateDuration,
+					Description:  "Delay each record read by up to this long (e.g., `200ms`) so reads of the same zone can be merged into one zone enumeration. The snapshot serves later reads until the provider writes. Off when unset.",
+				},
+				"max_output_size_mb": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      256,
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "Largest samba-tool output, in MiB, a single call may buffer before it is stopped with an error, guarding memory against pathological names. Zone enumerations stream their output and are not limited. `0` is unlimited.",
+				},
+				"samba_options": {
+					Type:         schema.TypeMap,
+					Optional:     true,
+					Elem:         &schema.Schema{Type: schema.TypeString},
+					ValidateFunc: validateSambaOptions,
+					Description: "smb.conf settings passed to every samba-tool call as `--option=name=value`, e.g. " +
+						"`client max protocol`, `client ipc signing` or `client use spnego`, for hardened networks where the defaults are blocked.",
+				},
+				"change_summary_file": {
+					Type:     schema.TypeString,
+					Optional: true,
+					Description: "Write the record changes each run plans (action, name, type, before and after values) to this JSON file, " +
+						"for change-management tooling to attach to tickets. The file is replaced at the start of every run.",
+				},
+				"dns_servers": {
+					Type:     schema.TypeList,
+					Optional: true,
+					MinItems: 2,
+					Elem:     &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.NoZeroValues},
+					Description: "DCs that host the same AD-integrated zones (e.g., `[\"dc01.example.com\", \"dc02.example.com\"]`). Failures are tracked per DC during the run; " +
+						"when a DC keeps failing, its circuit breaker opens and operations for resources whose `server` is that DC run against the next healthy DC in the list.",
+				},
+				"serial_check": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      serialCheckOff,
+					ValidateFunc: validation.StringInSlice([]string{serialCheckOff, serialCheckWarn, serialCheckFail}, false),
+					Description: "Compare the zone's SOA serial before and after every record write: `warn` reports writes that left it unchanged, `fail` fails them. " +
+						"On DCs running the BIND9 DLZ backend, a serial that did not move usually means the write went to a partition the DNS server does not load. Each checked write costs two SOA queries.",
+				},
+				"circuit_breaker":    circuitBreakerSchema(),
+				"operation_order":    operationOrderSchema(),
+				"manifest":           manifestSchema(),
+				"maintenance_window": maintenanceWindowSchema(),
+				"zone_credentials":   zoneCredentialsSchema(),
+				"profile":            profileBlockSchema(),
+				"retry":              retrySchema("Retry transient samba-tool failures (timeouts, refused or reset connections). Without this block nothing is retried."),
 			},
 			ResourcesMap: map[string]*schema.Resource{
-				"sambadns_record": resourceRecord(),
+				"sambadns_record":       resourceRecord(),
+				"sambadns_round_robin":  resourceRoundRobin(),
+				"sambadns_split_record": resourceSplitRecord(),
+				"sambadns_aliases":      resourceAliases(),
+				"sambadns_mx_set":       resourceMXSet(),
+				"sambadns_delegation":   resourceDelegation(),
+				"sambadns_zone_serial":  resourceZoneSerial(),
+				"sambadns_zone":         resourceZone(),
+				"sambadns_record_set":   resourceRecordSet(),
+
+				"sambadns_zone_forwarder_order": resourceZoneForwarderOrder(),
 			},
 			DataSourcesMap: map[string]*schema.Resource{
-				"sambadns_record": dataSourceRecord(),
+				"sambadns_record":                dataSourceRecord(),
+				"sambadns_capabilities":          dataSourceCapabilities(),
+				"sambadns_name":                  dataSourceName(),
+				"sambadns_duplicate_report":      dataSourceDuplicateReport(),
+				"sambadns_preflight":             dataSourcePreflight(),
+				"sambadns_zone_delegation_check": dataSourceZoneDelegationCheck(),
+				"sambadns_name_available":        dataSourceNameAvailable(),
+				"sambadns_drift":                 dataSourceDrift(),
+				"sambadns_msdcs":                 dataSourceMSDCS(),
+				"sambadns_dc_locator":            dataSourceDCLocator(),
+				"sambadns_records":               dataSourceRecords(),
+				"sambadns_reverse_name":          dataSourceReverseName(),
 			},
 		}
 
+		for name, r := range p.ResourcesMap {
+			applyRenames(r, true)
+			guardMaintenanceWindow(name, r)
+			orderOperations(r)
+		}
+		for _, r := range p.DataSourcesMap {
+			applyRenames(r, false)
+		}
 		p.ConfigureContextFunc = configure(version, p)
 
 		return p
@@ -48,7 +207,20 @@ func New(version string) func() *schema.Provider {
 
 // apiClient holds the configured samba client
 type apiClient struct {
-	client *SambaClient
+	client       *SambaClient
+	allowedCIDRs []*net.IPNet
+	namePolicy   *regexp.Regexp
+	nameDenylist map[string]bool
+	ownerID      string
+	ownerPrefix  string
+	failures     *failureTracker
+	version      string
+	backend      string
+	reads        *readBatcher
+	profiles     map[string]*SambaClient
+	zoneClient   *SambaClient
+	changes      *changeSummary
+	queue        *operationQueue
 }
 
 func configure(version string, p *schema.Provider) func(context.Context, *schema.ResourceData) (interface{}, diag.Diagnostics) {
@@ -64,12 +236,201 @@ func configure(version string, p *schema.Provider) func(context.Context, *schema
 			password = v
 		}
 
-		if username == "" || password == "" {
+		fileBackend := d.Get("backend").(string) == "file"
+		if fileBackend && d.Get("backend_file").(string) == "" {
+			return nil, diag.Errorf("backend_file is required when backend is \"file\"")
+		}
+		if !fileBackend && (username == "" || password == "") {
 			return nil, diag.Errorf("username and password are required")
 		}
 
+		allowedCIDRs, err := parseCIDRs(d.Get("allowed_cidrs").([]interface{}))
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+
+		var namePolicy *regexp.Regexp
+		if v := d.Get("name_policy_regex").(string); v != "" {
+			namePolicy, err = regexp.Compile(v)
+			if err != nil {
+				return nil, diag.Errorf("invalid name_policy_regex: %s", err)
+			}
+		}
+
+		nameDenylist := make(map[string]bool)
+		for _, v := range d.Get("name_denylist").(*schema.Set).List() {
+			nameDenylist[strings.ToLower(v.(string))] = true
+		}
+
 		client := NewSambaClient(username, password)
+		if fileBackend {
+			client.runner = &fileRunner{path: d.Get("backend_file").(string)}
+		}
+		client.runner = withOutputLimit(client.runner, int64(d.Get("max_output_size_mb").(int))<<20)
+		var container map[string]interface{}
+		if blocks := d.Get("container").([]interface{}); len(blocks) > 0 && blocks[0] != nil {
+			container = blocks[0].(map[string]interface{})
+			client.Command = containerCommand(container)
+		}
+		if raw := d.Get("samba_options").(map[string]interface{}); len(raw) > 0 {
+			client.Options = make(map[string]string, len(raw))
+			for k, v := range raw {
+				client.Options[k] = v.(string)
+			}
+		}
+		if policy, ok := expandRetryPolicy(d.Get("retry").([]interface{})); ok {
+			client.Retry = policy
+		}
+		if raw := d.Get("dns_servers").([]interface{}); len(raw) > 0 {
+			if err := validateDNSServers(raw); err != nil {
+				return nil, diag.FromErr(err)
+			}
+			client.pool = newDCPool(setToStrings(raw), d.Get("circuit_breaker").([]interface{}))
+		}
+		if err := checkCommandAvailable(client.Command, client.runner); err != nil {
+			return nil, diag.Diagnostics{{
+				Severity: diag.Error,
+				Summary:  "samba-tool is not available",
+				Detail:   err.Error(),
+			}}
+		}
+
+		if blocks := d.Get("manifest").([]interface{}); len(blocks) > 0 && blocks[0] != nil {
+			if client.manifest, err = newMutationManifest(blocks[0].(map[string]interface{}), version); err != nil {
+				return nil, diag.FromErr(err)
+			}
+		}
+
+		if blocks := d.Get("maintenance_window").([]interface{}); len(blocks) > 0 && blocks[0] != nil {
+			override := strings.EqualFold(os.Getenv("SAMBADNS_MAINTENANCE_OVERRIDE"), "true")
+			if client.window, err = newMaintenanceWindow(blocks[0].(map[string]interface{}), override); err != nil {
+				return nil, diag.FromErr(err)
+			}
+		}
 
-		return &apiClient{client: client}, nil
+		client.serials = newSerialCheck(d.Get("serial_check").(string))
+
+		profiles, err := expandProfiles(client, d.Get("profile").([]interface{}))
+		if err != nil {
+			return nil, diag.FromErr(err)
+		}
+		var zoneClient *SambaClient
+		if blocks := d.Get("zone_credentials").([]interface{}); len(blocks) > 0 && blocks[0] != nil {
+			if zoneClient, err = withIdentity(client, blocks[0].(map[string]interface{})); err != nil {
+				return nil, diag.Errorf("zone_credentials: %s", err)
+			}
+		}
+
+		var changes *changeSummary
+		if v := d.Get("change_summary_file").(string); v != "" {
+			if changes, err = newChangeSummary(v); err != nil {
+				return nil, diag.FromErr(err)
+			}
+		}
+
+		var reads *readBatcher
+		if v := d.Get("read_batch_window").(string); v != "" {
+			window, _ := time.ParseDuration(v)
+			reads = newReadBatcher(window)
+		}
+
+		backend := backendName(container)
+		if fileBackend {
+			backend = "file:" + d.Get("backend_file").(string)
+		}
+
+		return &apiClient{
+			client:       client,
+			allowedCIDRs: allowedCIDRs,
+			namePolicy:   namePolicy,
+			nameDenylist: nameDenylist,
+			ownerID:      d.Get("owner_id").(string),
+			ownerPrefix:  d.Get("owner_record_prefix").(string),
+			failures:     newFailureTracker(),
+			version:      version,
+			backend:      backend,
+			reads:        reads,
+			profiles:     profiles,
+			zoneClient:   zoneClient,
+			changes:      changes,
+			queue:        newOperationQueue(d.Get("operation_order").([]interface{})),
+		}, nil
+	}
+}
+
+// parseCIDRs converts a list of CIDR strings from configuration into networks
+func parseCIDRs(raw []interface{}) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, v := range raw {
+		_, n, err := net.ParseCIDR(v.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", v, err)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// checkNamePolicy enforces the provider-level naming policy on a record name
+func (a *apiClient) checkNamePolicy(name string) error {
+	if a.nameDenylist[strings.ToLower(name)] {
+		return fmt.Errorf("record name %q is on the provider name_denylist", name)
+	}
+	if a.namePolicy != nil && !a.namePolicy.MatchString(name) {
+		return fmt.Errorf("record name %q does not match the provider name_policy_regex %q", name, a.namePolicy.String())
+	}
+	return nil
+}
+
+// ownerRecord returns the ownership TXT companion of a record, or nil when owner tracking is off
+// The companion lives at <prefix>.<name> and names the owner and the record type, so several
+// managed types at one name each keep their own value
+func (a *apiClient) ownerRecord(r DNSRecord) *DNSRecord {
+	if a.ownerID == "" {
+		return nil
+	}
+
+	name := a.ownerPrefix
+	if r.Name != "@" && r.Name != "" {
+		// A wildcard label cannot be prefixed, so it is spelled out
+		name = a.ownerPrefix + "." + strings.ReplaceAll(r.Name, "*", "_wildcard")
+	}
+
+	return &DNSRecord{
+		Server: r.Server,
+		Zone:   r.Zone,
+		Name:   name,
+		Type:   "TXT",
+		Value:  fmt.Sprintf("heritage=terraform;sambadns/owner=%s;sambadns/type=%s", a.ownerID, strings.ToUpper(r.Type)),
+	}
+}
+
+// containerCommand builds the argv prefix that runs samba-tool inside a container
+func containerCommand(cfg map[string]interface{}) []string {
+	runtime := cfg["runtime"].(string)
+	name := cfg["name"].(string)
+
+	if runtime != "kubectl" {
+		return []string{runtime, "exec", "-i", name, "samba-tool"}
+	}
+
+	cmd := []string{"kubectl", "exec", "-i"}
+	if ns := cfg["namespace"].(string); ns != "" {
+		cmd = append(cmd, "-n", ns)
+	}
+	cmd = append(cmd, name)
+	if container := cfg["container"].(string); container != "" {
+		cmd = append(cmd, "-c", container)
+	}
+	return append(cmd, "--", "samba-tool")
+}
+
+// validateSambaOptions rejects option names samba-tool would misparse
+func validateSambaOptions(v interface{}, k string) (warnings []string, errs []error) {
+	for name := range v.(map[string]interface{}) {
+		if strings.TrimSpace(name) == "" || strings.Contains(name, "=") {
+			errs = append(errs, fmt.Errorf("%q: invalid smb.conf option name %q", k, name))
+		}
 	}
+	return warnings, errs
 }
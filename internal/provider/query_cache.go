@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"strings"
+	"sync"
+)
+
+// queryCache shares the output of identical samba-tool dns query calls for the lifetime of the provider process
+// A data source reading a record and resources managing the same or sibling records ask for the same
+// names; concurrent and repeated calls with the same arguments and identity make one samba-tool call.
+// Entries are stamped with the write counter, so any write by the provider invalidates them all.
+// Answers and "does not exist" errors are kept; other failures are not. Clients cloned for
+// per-resource credentials share the parent's cache, keyed by identity
+type queryCache struct {
+	mu      sync.Mutex
+	entries map[string]*queryEntry
+}
+
+type queryEntry struct {
+	ready      chan struct{}
+	generation int64
+	output     string
+	err        error
+	// failed is set when the call's failure is not worth sharing; waiters run their own call
+	failed bool
+}
+
+func newQueryCache() *queryCache {
+	return &queryCache{entries: make(map[string]*queryEntry)}
+}
+
+// isCachedQuery reports whether samba-tool arguments are a record query the cache may answer
+func isCachedQuery(args []string) bool {
+	return len(args) >= 2 && args[0] == "dns" && args[1] == "query"
+}
+
+// queryKey identifies a query by the identity running it and its arguments
+func (c *SambaClient) queryKey(args []string) string {
+	return strings.ToLower(c.identity() + "\x00" + c.Realm + "\x00" + strings.Join(args, "\x00"))
+}
+
+// get returns the output of the query under key, calling fetch unless a call made since the
+// last write (generation) already returned it or is in flight
+func (qc *queryCache) get(key string, generation int64, fetch func() (string, error)) (string, error) {
+	if qc == nil {
+		return fetch()
+	}
+
+	qc.mu.Lock()
+	if entry, ok := qc.entries[key]; ok && entry.generation == generation {
+		qc.mu.Unlock()
+		<-entry.ready
+		if entry.failed {
+			return fetch()
+		}
+		return entry.output, entry.err
+	}
+	entry := &queryEntry{ready: make(chan struct{}), generation: generation}
+	qc.entries[key] = entry
+	qc.mu.Unlock()
+
+	entry.output, entry.err = fetch()
+	if entry.err != nil && !isNotExistError(entry.err) {
+		entry.failed = true
+		qc.mu.Lock()
+		if qc.entries[key] == entry {
+			delete(qc.entries, key)
+		}
+		qc.mu.Unlock()
+	}
+	close(entry.ready)
+	return entry.output, entry.err
+}
@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// writeCounter counts samba-tool writes so cached zone snapshots can tell when they are stale
+// Clients cloned for per-resource credentials share the parent's counter
+type writeCounter struct {
+	n int64
+}
+
+func (w *writeCounter) add() {
+	if w != nil {
+		atomic.AddInt64(&w.n, 1)
+	}
+}
+
+func (w *writeCounter) value() int64 {
+	if w == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&w.n)
+}
+
+// isWriteCommand reports whether samba-tool arguments change DNS data
+func isWriteCommand(args []string) bool {
+	if len(args) < 2 || args[0] != "dns" {
+		return false
+	}
+	switch args[1] {
+	case "query", "serverinfo", "zoneinfo", "zonelist":
+		return false
+	}
+	return true
+}
+
+// readBatcher merges record reads against the same zone into one zone enumeration
+// The first read of a zone waits for the window; reads arriving meanwhile join it, and
+// if any did, the zone is enumerated once and every member is answered from the result.
+// The snapshot keeps answering later reads until the provider performs a write.
+type readBatcher struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	batches map[string]*readBatch
+}
+
+type readBatch struct {
+	ready      chan struct{}
+	members    int
+	done       bool
+	generation int64
+	records    map[string]DNSRecord // keyed by snapshotKey; nil when the zone was not enumerated
+	err        error
+}
+
+func newReadBatcher(window time.Duration) *readBatcher {
+	return &readBatcher{window: window, batches: make(map[string]*readBatch)}
+}
+
+// queryRecord answers QueryRecord, from a zone snapshot when reads were batched
+func (b *readBatcher) queryRecord(ctx context.Context, c *SambaClient, server, zone, name, recordType string) (*DNSRecord, error) {
+	if b == nil {
+		return c.QueryRecord(server, zone, name, recordType)
+	}
+	key := strings.ToLower(server + "/" + zone + "/" + c.Username + "/" + c.Ccache)
+
+	b.mu.Lock()
+	batch := b.batches[key]
+	switch {
+	case batch != nil && batch.done && batch.records != nil && batch.generation == c.writes.value():
+		// Fresh snapshot
+		b.mu.Unlock()
+		return batch.lookup(server, zone, name, recordType), nil
+
+	case batch != nil && !batch.done:
+		batch.members++
+		b.mu.Unlock()
+		select {
+		case <-batch.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if batch.err != nil || batch.records == nil {
+			return c.QueryRecord(server, zone, name, recordType)
+		}
+		return batch.lookup(server, zone, name, recordType), nil
+	}
+
+	batch = &readBatch{ready: make(chan struct{}), members: 1}
+	b.batches[key] = batch
+	b.mu.Unlock()
+
+	select {
+	case <-time.After(b.window):
+	case <-ctx.Done():
+	}
+
+	b.mu.Lock()
+	members := batch.members
+	b.mu.Unlock()
+
+	if members > 1 {
+		batch.generation = c.writes.value()
+		records, err := c.ListZoneRecords(server, zone)
+		if err == nil {
+			batch.records = make(map[string]DNSRecord, len(records))
+			// Keep the first record of each name and type, as QueryRecord does
+			for i := len(records) - 1; i >= 0; i-- {
+				batch.records[snapshotKey(records[i].Name, records[i].Type)] = records[i]
+			}
+		}
+		batch.err = err
+	}
+
+	b.mu.Lock()
+	batch.done = true
+	b.mu.Unlock()
+	close(batch.ready)
+
+	if batch.records == nil {
+		return c.QueryRecord(server, zone, name, recordType)
+	}
+	return batch.lookup(server, zone, name, recordType), nil
+}
+
+// lookup returns the snapshot's record of a name and type, nil when absent
+func (batch *readBatch) lookup(server, zone, name, recordType string) *DNSRecord {
+	r, ok := batch.records[snapshotKey(name, recordType)]
+	if !ok {
+		return nil
+	}
+	r.Server = server
+	r.Zone = zone
+	r.Name = name
+	return &r
+}
+
+// snapshotKey identifies a record of a zone snapshot by name and type
+func snapshotKey(name, recordType string) string {
+	return strings.ToLower(name) + "/" + strings.ToUpper(recordType)
+}
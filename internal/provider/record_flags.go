@@ -0,0 +1,126 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Record flags are the dwFlags samba-tool prints with each record (MS-DNSP 2.2.2.2.5): the high bits
+// are DNS_RPC_FLAG_* values and the low byte is the record's rank
+const (
+	dnsRPCFlagCacheData        = 0x80000000
+	dnsRPCFlagZoneRoot         = 0x40000000
+	dnsRPCFlagAuthZoneRoot     = 0x20000000
+	dnsRPCFlagZoneDelegation   = 0x10000000
+	dnsRPCFlagRecordDefaultTTL = 0x08000000
+	dnsRPCFlagRecordTTLChange  = 0x04000000
+	dnsRPCFlagRecordCreatePTR  = 0x02000000
+	dnsRPCFlagNodeSticky       = 0x01000000
+	dnsRPCFlagNodeComplete     = 0x00800000
+	dnsRPCFlagRecordWireFormat = 0x00100000
+	dnsRPCFlagOpenACL          = 0x00040000
+	dnsRPCFlagAgingOn          = 0x00020000
+	dnsRPCFlagSuppressNotify   = 0x00010000
+
+	dnsRankMask = 0xff
+)
+
+// recordFlagNames names the DNS_RPC_FLAG_* bits, for the flags attribute
+var recordFlagNames = map[uint32]string{
+	dnsRPCFlagCacheData:        "DNS_RPC_FLAG_CACHE_DATA",
+	dnsRPCFlagZoneRoot:         "DNS_RPC_FLAG_ZONE_ROOT",
+	dnsRPCFlagAuthZoneRoot:     "DNS_RPC_FLAG_AUTH_ZONE_ROOT",
+	dnsRPCFlagZoneDelegation:   "DNS_RPC_FLAG_ZONE_DELEGATION",
+	dnsRPCFlagRecordDefaultTTL: "DNS_RPC_FLAG_RECORD_DEFAULT_TTL",
+	dnsRPCFlagRecordTTLChange:  "DNS_RPC_FLAG_RECORD_TTL_CHANGE",
+	dnsRPCFlagRecordCreatePTR:  "DNS_RPC_FLAG_RECORD_CREATE_PTR",
+	dnsRPCFlagNodeSticky:       "DNS_RPC_FLAG_NODE_STICKY",
+	dnsRPCFlagNodeComplete:     "DNS_RPC_FLAG_NODE_COMPLETE",
+	dnsRPCFlagRecordWireFormat: "DNS_RPC_FLAG_RECORD_WIRE_FORMAT",
+	dnsRPCFlagOpenACL:          "DNS_RPC_FLAG_OPEN_ACL",
+	dnsRPCFlagAgingOn:          "DNS_RPC_FLAG_AGING_ON",
+	dnsRPCFlagSuppressNotify:   "DNS_RPC_FLAG_SUPPRESS_NOTIFY",
+}
+
+// recordRanks are the ranks the rank argument accepts, by name
+// Other ranks (cached data, outside data) are reported by their hex value
+var recordRanks = map[string]uint32{
+	"zone":      0xf0,
+	"ns-glue":   0x82,
+	"glue":      0x20,
+	"root-hint": 0x08,
+}
+
+// recordRankNames lists the names of recordRanks, for validation
+var recordRankNames = []string{"zone", "glue", "ns-glue", "root-hint"}
+
+// parseRecordFlags parses the hex flags field of record metadata, e.g. "600000f0"
+// Records printed without flags are zone data
+func parseRecordFlags(raw string) uint32 {
+	flags, err := strconv.ParseUint(raw, 16, 32)
+	if err != nil {
+		return recordRanks["zone"]
+	}
+	return uint32(flags)
+}
+
+// decodeRecordFlags returns the names of the DNS_RPC_FLAG_* bits set in flags, sorted
+func decodeRecordFlags(flags uint32) []string {
+	names := []string{}
+	for bit, name := range recordFlagNames {
+		if flags&bit != 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// recordRank names the rank in the low byte of flags, or spells it in hex when it has no name
+func recordRank(flags uint32) string {
+	rank := flags & dnsRankMask
+	for name, v := range recordRanks {
+		if v == rank {
+			return name
+		}
+	}
+	return fmt.Sprintf("0x%02x", rank)
+}
+
+// errRecordFlagsReadOnly is returned when the backend cannot write record flags
+var errRecordFlagsReadOnly = errors.New("record flags cannot be written through samba-tool: dns add and dns update take no flags")
+
+// recordFlagWriter is implemented by runners that can change the flags of a stored record
+// samba-tool cannot, so only the file backend does
+type recordFlagWriter interface {
+	writeRecordFlags(server, zone, name, recordType, value string, flags uint32) error
+}
+
+// canWriteRecordFlags reports whether the client's backend can write record flags
+func (c *SambaClient) canWriteRecordFlags() bool {
+	_, ok := c.runner.(recordFlagWriter)
+	return ok
+}
+
+// SetRecordFlags replaces the rank of a stored record and sets or clears DNS_RPC_FLAG_SUPPRESS_NOTIFY,
+// keeping its other flags
+func (c *SambaClient) SetRecordFlags(r DNSRecord, rank string, suppressNotify bool) error {
+	writer, ok := c.runner.(recordFlagWriter)
+	if !ok {
+		return errRecordFlagsReadOnly
+	}
+	value, ok := recordRanks[rank]
+	if !ok {
+		return fmt.Errorf("unknown record rank %q (expected one of %s)", rank, strings.Join(recordRankNames, ", "))
+	}
+	flags := r.Flags&^(dnsRankMask|dnsRPCFlagSuppressNotify) | value
+	if suppressNotify {
+		flags |= dnsRPCFlagSuppressNotify
+	}
+	// Cached queries still hold the old flags
+	c.writes.add()
+	return writer.writeRecordFlags(r.Server, r.Zone, r.Name, strings.ToUpper(r.Type), r.Value, flags)
+}
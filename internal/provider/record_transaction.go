@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// recordWrite is a completed write and the write that reverses it
+type recordWrite struct {
+	desc string
+	undo func(c *SambaClient) error
+}
+
+// recordTransaction applies the writes for one name, such as a record with its ownership TXT and PTR, as a unit
+// samba-tool has no transactions, so each successful write remembers its inverse; when a later write
+// fails, the inverses run in reverse order and the records end up as they were before the apply
+type recordTransaction struct {
+	c    *SambaClient
+	done []recordWrite
+}
+
+func newRecordTransaction(c *SambaClient) *recordTransaction {
+	return &recordTransaction{c: c}
+}
+
+// create adds r, to be deleted again on rollback
+// A record that already existed is not the transaction's to delete, so it gets no undo
+func (t *recordTransaction) create(r DNSRecord) error {
+	changed, err := t.c.createRecord(r)
+	if err != nil || !changed {
+		return err
+	}
+	t.done = append(t.done, recordWrite{
+		desc: fmt.Sprintf("create of %s %s", joinFQDN(r.Name, r.Zone), r.Type),
+		undo: func(c *SambaClient) error { return c.DeleteRecord(r) },
+	})
+	return nil
+}
+
+// delete removes r, to be added again on rollback
+// A record that was already gone is not recreated
+func (t *recordTransaction) delete(r DNSRecord) error {
+	changed, err := t.c.deleteRecord(r)
+	if err != nil || !changed {
+		return err
+	}
+	t.done = append(t.done, recordWrite{
+		desc: fmt.Sprintf("delete of %s %s", joinFQDN(r.Name, r.Zone), r.Type),
+		undo: func(c *SambaClient) error { return c.CreateRecord(r) },
+	})
+	return nil
+}
+
+// rollback reverses the writes done so far and returns err, annotated with the outcome
+// Undoing puts back what was there, so the maintenance window does not apply to it
+func (t *recordTransaction) rollback(err error) error {
+	var failed []string
+	c := t.c.withWindowCleared(true)
+	for i := len(t.done) - 1; i >= 0; i-- {
+		if undoErr := t.done[i].undo(c); undoErr != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", t.done[i].desc, undoErr))
+		}
+	}
+	switch {
+	case len(failed) > 0:
+		return fmt.Errorf("%w; rolling back failed, so the records are partially changed (could not reverse the %s)", err, strings.Join(failed, "; "))
+	case len(t.done) > 0:
+		return fmt.Errorf("%w (rolled back %d earlier write(s) of this resource)", err, len(t.done))
+	}
+	return err
+}
+
+// ptrRecord returns the PTR record in ptrZone that points back at an A or AAAA record
+func ptrRecord(r DNSRecord, ptrZone string) (*DNSRecord, error) {
+	if ptrZone == "" {
+		return nil, nil
+	}
+	if r.Type != "A" && r.Type != "AAAA" {
+		return nil, fmt.Errorf("ptr_zone is only supported for A and AAAA records, not %s", r.Type)
+	}
+	if strings.Contains(r.Name, "*") {
+		return nil, fmt.Errorf("ptr_zone is not supported for wildcard record %s", r.Name)
+	}
+	ip := net.ParseIP(r.Value)
+	if ip == nil {
+		return nil, fmt.Errorf("ptr_zone: %q is not an IP address", r.Value)
+	}
+	name, ok := relativeName(reverseName(ip), ptrZone)
+	if !ok {
+		return nil, fmt.Errorf("ptr_zone: %s is outside reverse zone %s (its reverse name is %s)", r.Value, ptrZone, reverseName(ip))
+	}
+	return &DNSRecord{
+		Server: r.Server,
+		Zone:   strings.TrimSuffix(ptrZone, "."),
+		Name:   name,
+		Type:   "PTR",
+		Value:  joinFQDN(r.Name, r.Zone),
+	}, nil
+}
+
+// validatePTRZone fails the plan when ptr_zone cannot hold the record's PTR
+func validatePTRZone(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("ptr_zone") || !d.NewValueKnown("value") || !d.NewValueKnown("name") || !d.NewValueKnown("zone") {
+		return nil
+	}
+	_, err := ptrRecord(DNSRecord{
+		Server: d.Get("dns_server").(string),
+		Zone:   d.Get("zone").(string),
+		Name:   d.Get("name").(string),
+		Type:   strings.ToUpper(d.Get("type").(string)),
+		Value:  d.Get("value").(string),
+	}, d.Get("ptr_zone").(string))
+	return err
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+)
+
+// newFileTestClient returns a client backed by a file holding the zone example.com
+func newFileTestClient(t *testing.T) *SambaClient {
+	t.Helper()
+	c := NewSambaClient("", "")
+	c.runner = &fileRunner{path: t.TempDir() + "/dns.json"}
+	if err := c.CreateZone("dc", "example.com", "domain"); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// TestRollbackOnlyUndoesChanges checks that records the transaction did not add or remove survive a rollback
+func TestRollbackOnlyUndoesChanges(t *testing.T) {
+	c := newFileTestClient(t)
+	existing := DNSRecord{Server: "dc", Zone: "example.com", Name: "web", Type: "A", Value: "10.0.0.1"}
+	if err := c.CreateRecord(existing); err != nil {
+		t.Fatal(err)
+	}
+	added := DNSRecord{Server: "dc", Zone: "example.com", Name: "web", Type: "TXT", Value: "owner"}
+	missing := DNSRecord{Server: "dc", Zone: "example.com", Name: "gone", Type: "A", Value: "10.0.0.2"}
+
+	tx := newRecordTransaction(c)
+	for _, step := range []func() error{
+		func() error { return tx.create(existing) },
+		func() error { return tx.delete(missing) },
+		func() error { return tx.create(added) },
+	} {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(tx.done) != 1 {
+		t.Fatalf("recorded %d undos, want 1 for the one write that changed anything", len(tx.done))
+	}
+	tx.rollback(errors.New("later write failed"))
+
+	if r, err := c.QueryRecord("dc", "example.com", "web", "A"); err != nil || r == nil {
+		t.Fatalf("record that existed before the transaction was removed by the rollback (%v)", err)
+	}
+	if r, err := c.QueryRecord("dc", "example.com", "gone", "A"); err != nil || r != nil {
+		t.Fatalf("rollback created a record that never existed: %+v (%v)", r, err)
+	}
+	if r, err := c.QueryRecord("dc", "example.com", "web", "TXT"); err != nil || r != nil {
+		t.Fatalf("rollback kept the record the transaction added: %+v (%v)", r, err)
+	}
+}
@@ -0,0 +1,319 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// resolverFor returns a resolver sending its queries to server on port 53
+func resolverFor(server string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, net.JoinHostPort(server, "53"))
+		},
+	}
+}
+
+// recordFQDN returns the fully qualified name a record resolves at
+// A wildcard label is replaced with a concrete one so the wildcard can be exercised
+func recordFQDN(name, zone string) string {
+	zone = trimRoot(zone)
+	if name == "@" || name == "" {
+		return zone + "."
+	}
+	if strings.HasPrefix(name, "*") {
+		name = "sambadns-verify" + strings.TrimPrefix(name, "*")
+	}
+	return name + "." + zone + "."
+}
+
+// lookupValues resolves fqdn for a record type and returns the answers in samba-tool value format
+func lookupValues(ctx context.Context, r *net.Resolver, fqdn, recordType string) ([]string, error) {
+	var values []string
+
+	switch strings.ToUpper(recordType) {
+	case "A", "AAAA":
+		network := "ip4"
+		if strings.ToUpper(recordType) == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := r.LookupIP(ctx, network, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			// net.IP prints an IPv4-mapped AAAA answer as plain IPv4
+			if network == "ip6" && ip.To4() != nil {
+				values = append(values, "::ffff:"+ip.String())
+				continue
+			}
+			values = append(values, ip.String())
+		}
+	case "CNAME":
+		target, err := r.LookupCNAME(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, target)
+	case "TXT":
+		txts, err := r.LookupTXT(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, txts...)
+	case "MX":
+		mxs, err := r.LookupMX(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			values = append(values, fmt.Sprintf("%s %d", strings.TrimSuffix(mx.Host, "."), mx.Pref))
+		}
+	case "NS":
+		nss, err := r.LookupNS(ctx, fqdn)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			values = append(values, ns.Host)
+		}
+	case "SRV":
+		_, srvs, err := r.LookupSRV(ctx, "", "", fqdn)
+		if err != nil {
+			return nil, err
+		}
+		for _, srv := range srvs {
+			values = append(values, fmt.Sprintf("%s %d %d %d", strings.TrimSuffix(srv.Target, "."), srv.Port, srv.Priority, srv.Weight))
+		}
+	case "PTR":
+		ip := reverseNameToIP(fqdn)
+		if ip == nil {
+			return nil, fmt.Errorf("%s is not a reverse lookup name", fqdn)
+		}
+		names, err := r.LookupAddr(ctx, ip.String())
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, names...)
+	default:
+		return nil, fmt.Errorf("resolution of %s records is not supported", recordType)
+	}
+
+	return values, nil
+}
+
+// resolvedValueMatches compares a configured value against a resolved answer
+func resolvedValueMatches(recordType, want, got string) bool {
+	switch strings.ToUpper(recordType) {
+	case "A":
+		return normalizeIPv6(want) == normalizeIPv6(got)
+	case "TXT":
+		return strings.Trim(want, "\"'") == got
+	default:
+		return recordValuesEqual(recordType, want, got)
+	}
+}
+
+// checkResolvable rejects names the Go resolver cannot look up
+// It only accepts hostname-like labels, so a name with RFC 1035 escapes would fail until the timeout
+func checkResolvable(fqdn string) error {
+	if strings.Contains(fqdn, "\\") {
+		return fmt.Errorf("%s has escaped characters, which the resolver used for verification cannot look up", fqdn)
+	}
+	return nil
+}
+
+// verifyResolution queries the resolver until it serves value for the record, or timeout elapses
+func verifyResolution(ctx context.Context, r *net.Resolver, fqdn, recordType, value string, timeout time.Duration) error {
+	if err := checkResolvable(fqdn); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	var lastErr error
+
+	for {
+		values, err := lookupValues(ctx, r, fqdn, recordType)
+		if err == nil {
+			for _, v := range values {
+				if resolvedValueMatches(recordType, value, v) {
+					return nil
+				}
+			}
+			lastErr = fmt.Errorf("answer %v does not contain %q", values, value)
+		} else {
+			lastErr = err
+		}
+
+		if time.Now().After(deadline) {
+			return lastErr
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// reverseNameToIP converts an in-addr.arpa or ip6.arpa name back to its address
+func reverseNameToIP(fqdn string) net.IP {
+	name := strings.ToLower(strings.TrimSuffix(fqdn, "."))
+
+	if strings.HasSuffix(name, ".in-addr.arpa") {
+		parts := strings.Split(strings.TrimSuffix(name, ".in-addr.arpa"), ".")
+		if len(parts) != 4 {
+			return nil
+		}
+		for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+			parts[i], parts[j] = parts[j], parts[i]
+		}
+		return net.ParseIP(strings.Join(parts, ".")).To4()
+	}
+
+	if strings.HasSuffix(name, ".ip6.arpa") {
+		nibbles := strings.Split(strings.TrimSuffix(name, ".ip6.arpa"), ".")
+		if len(nibbles) != 32 {
+			return nil
+		}
+		ip := make(net.IP, net.IPv6len)
+		for i := 0; i < 32; i++ {
+			v, err := strconv.ParseUint(nibbles[31-i], 16, 8)
+			if err != nil || len(nibbles[31-i]) != 1 {
+				return nil
+			}
+			ip[i/2] |= byte(v) << (4 * uint(1-i%2))
+		}
+		return ip
+	}
+
+	return nil
+}
+
+// targetLookupTimeout bounds each resolver's answer when validating a record target
+const targetLookupTimeout = 10 * time.Second
+
+// recordTarget returns the host name a CNAME, MX, SRV or NS value points at
+// ok is false for other types and for the SRV "." target, which means the service is not offered
+func recordTarget(recordType, value string) (target string, ok bool) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return "", false
+	}
+	switch strings.ToUpper(recordType) {
+	case "CNAME", "NS", "MX", "SRV":
+		// MX and SRV values lead with the host, as samba-tool expects them
+		if fields[0] == "." {
+			return "", false
+		}
+		return strings.TrimSuffix(fields[0], "."), true
+	}
+	return "", false
+}
+
+// validateRecordTarget fails when the target of a CNAME, MX, SRV or NS value does not resolve
+// The DC is asked first, so targets in its own zones count even when the local resolver
+// cannot see them; the local resolver covers targets elsewhere
+func validateRecordTarget(ctx context.Context, server, recordType, value string) error {
+	target, ok := recordTarget(recordType, value)
+	if !ok {
+		return nil
+	}
+
+	var errs []string
+	for _, r := range []struct {
+		name     string
+		resolver *net.Resolver
+	}{
+		{server, resolverFor(server)},
+		{"the local resolver", net.DefaultResolver},
+	} {
+		lookupCtx, cancel := context.WithTimeout(ctx, targetLookupTimeout)
+		_, err := r.resolver.LookupHost(lookupCtx, target)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", r.name, err))
+	}
+	return fmt.Errorf("%s target %s does not resolve (%s); create the target first, or unset validate_target",
+		strings.ToUpper(recordType), target, strings.Join(errs, "; "))
+}
+
+// canaryPollInterval is how often canary resolvers are asked for a changed record
+const canaryPollInterval = 2 * time.Second
+
+// canaryGrace is added to the cached TTL, for resolvers that answer with the TTL rounded up
+const canaryGrace = 5 * time.Second
+
+// watchCanaries polls recursive resolvers until each serves value or may still cache the old answer no longer
+// cachedTTL is how long a resolver may keep serving the previous answer: the old record's TTL, or the
+// zone's negative caching TTL for a new name. Progress is logged; resolvers that never serve the value become warnings
+func watchCanaries(ctx context.Context, resolvers []string, fqdn, recordType, value string, cachedTTL, maxWait time.Duration) diag.Diagnostics {
+	if err := checkResolvable(fqdn); err != nil {
+		return diag.Diagnostics{{Severity: diag.Warning, Summary: "canary resolvers were not checked", Detail: err.Error()}}
+	}
+	wait := cachedTTL + canaryGrace
+	if wait > maxWait {
+		wait = maxWait
+	}
+
+	var mu sync.Mutex
+	var diags diag.Diagnostics
+	var wg sync.WaitGroup
+	start := time.Now()
+
+	for _, server := range resolvers {
+		wg.Add(1)
+		go func(server string) {
+			defer wg.Done()
+			r := resolverFor(server)
+			var last string
+			for {
+				values, err := lookupValues(ctx, r, fqdn, recordType)
+				if err == nil {
+					for _, v := range values {
+						if resolvedValueMatches(recordType, value, v) {
+							log.Printf("[INFO] canary %s serves %s %s %q after %s", server, fqdn, recordType, value, time.Since(start).Round(time.Second))
+							return
+						}
+					}
+					last = fmt.Sprintf("%v", values)
+				} else {
+					last = err.Error()
+				}
+
+				elapsed := time.Since(start)
+				if elapsed >= wait {
+					mu.Lock()
+					defer mu.Unlock()
+					diags = append(diags, diag.Diagnostic{
+						Severity: diag.Warning,
+						Summary:  fmt.Sprintf("canary %s does not serve the new %s value of %s", server, recordType, fqdn),
+						Detail: fmt.Sprintf("After %s (cached TTL %s) it still answers %s. It may forward to an upstream that has not refreshed yet, "+
+							"or enforce a minimum cache TTL above the record's.", elapsed.Round(time.Second), cachedTTL, last),
+					})
+					return
+				}
+				log.Printf("[INFO] canary %s still answers %s for %s %s; waiting up to %s more", server, last, fqdn, recordType, (wait - elapsed).Round(time.Second))
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(canaryPollInterval):
+				}
+			}
+		}(server)
+	}
+	wg.Wait()
+	return diags
+}
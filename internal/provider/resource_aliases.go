@@ -0,0 +1,276 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceAliasesExample is the example configuration shown in the resource documentation
+const resourceAliasesExample = `
+resource "sambadns_aliases" "shop" {
+  server  = "dc01.example.com"
+  zone    = "example.com"
+  target  = "shop-lb.example.com"
+  aliases = ["shop", "store", "buy", "*.promo"]
+}
+`
+
+func resourceAliases() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Manages one CNAME per alias name, all pointing at the same target. Adding or removing an alias "+
+			"touches only that CNAME, which suits apps with dozens of vanity names.",
+			resourceAliasesExample, "terraform import sambadns_aliases.shop \"dc01.example.com/example.com/*.promo,buy,shop,store\""),
+
+		CreateContext: wrapCRUD(resourceAliasesCreate),
+		ReadContext:   wrapCRUD(resourceAliasesRead),
+		UpdateContext: wrapCRUD(resourceAliasesUpdate),
+		DeleteContext: wrapCRUD(resourceAliasesDelete),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		CustomizeDiff: customdiff.All(
+			validateAliasesPolicy,
+			estimateOperations(aliasesOperations),
+		),
+
+		Schema: withPlanEstimate(map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS zone name (e.g., example.com).",
+			},
+			"target": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateFunc:     validateNoTemplatePlaceholders,
+				DiffSuppressFunc: suppressTrailingDotDiff,
+				Description:      "FQDN every alias points at (e.g., app.example.com).",
+			},
+			"aliases": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Alias names, relative to the zone (e.g., `www`, `shop`, `*.promo`).",
+			},
+			"max_zone_records": maxZoneRecordsSchema(),
+			"profile":          profileSchema(),
+			"credentials":      credentialsSchema(),
+			"retry":            resourceRetrySchema(),
+			"write_metadata":   writeMetadataSchema(),
+		}),
+	}
+}
+
+// suppressTrailingDotDiff ignores a trailing dot difference on hostnames
+func suppressTrailingDotDiff(k, old, new string, d *schema.ResourceData) bool {
+	return recordValuesEqual("CNAME", old, new)
+}
+
+// buildAliasesID is a name ID whose name part lists the aliases, sorted and comma separated
+func buildAliasesID(server, zone string, aliases []string) string {
+	sorted := append([]string(nil), aliases...)
+	sort.Strings(sorted)
+	return buildNameID(server, zone, strings.Join(sorted, ","))
+}
+
+// parseAliasesID is the inverse of buildAliasesID
+func parseAliasesID(id string) (server, zone string, aliases []string, err error) {
+	server, zone, list, err := parseNameID(id)
+	if err != nil {
+		return "", "", nil, err
+	}
+	for _, alias := range strings.Split(list, ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	if len(aliases) == 0 {
+		return "", "", nil, fmt.Errorf("invalid ID format: %s (expected server/zone/alias1,alias2)", id)
+	}
+	return server, zone, aliases, nil
+}
+
+// aliasRecords builds the CNAME records for a list of aliases
+func aliasRecords(server, zone, target string, aliases []string) []DNSRecord {
+	records := make([]DNSRecord, 0, len(aliases))
+	for _, alias := range aliases {
+		records = append(records, DNSRecord{
+			Server: server,
+			Zone:   zone,
+			Name:   alias,
+			Type:   "CNAME",
+			Value:  target,
+		})
+	}
+	return records
+}
+
+// validateAliasesPolicy applies the provider naming policy to every alias at plan time
+func validateAliasesPolicy(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if m == nil || !d.NewValueKnown("aliases") {
+		return nil
+	}
+	for _, alias := range setToStrings(d.Get("aliases").(*schema.Set).List()) {
+		if err := m.(*apiClient).checkNamePolicy(alias); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aliasesOperations counts CNAME writes: one per added or removed alias, and a
+// delete plus create for every alias when the target changes
+func aliasesOperations(d *schema.ResourceDiff) (int, bool) {
+	ops, known := setChangeCount(d, "aliases")
+	if known && d.Id() != "" && d.HasChange("target") {
+		oldRaw, newRaw := d.GetChange("aliases")
+		ops = 2*oldRaw.(*schema.Set).Intersection(newRaw.(*schema.Set)).Len() + ops
+	}
+	return ops, known
+}
+
+func resourceAliasesCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+	target := d.Get("target").(string)
+	aliases := setToStrings(d.Get("aliases").(*schema.Set).List())
+
+	if err := checkZoneQuota(d, c, server, zone, len(aliases)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(buildAliasesID(server, zone, aliases))
+
+	if err := applyRecordChanges(c, aliasRecords(server, zone, target, aliases), nil); err != nil {
+		return diag.FromErr(err)
+	}
+
+	diags := setWriteMetadata(d, m)
+
+	return append(diags, resourceAliasesRead(ctx, d, m)...)
+}
+
+func resourceAliasesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The ID names the aliases, so an imported resource is read from them
+	server, zone, aliases, err := parseAliasesID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	target := d.Get("target").(string)
+
+	// Aliases that vanished or point elsewhere drop out of state, so the next plan puts them back;
+	// with none left the resource is kept, and the plan restores every alias
+	present := []string{}
+	for _, alias := range aliases {
+		record, err := c.QueryRecord(server, zone, alias, "CNAME")
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to query alias %s: %w", alias, err))
+		}
+		if record == nil {
+			continue
+		}
+		if target == "" {
+			// Imported: the first alias found gives the target
+			target = record.Value
+		}
+		if recordValuesEqual("CNAME", record.Value, target) {
+			present = append(present, alias)
+		}
+	}
+	sort.Strings(present)
+
+	state := newStateSetter(d)
+	state.set("dns_server", server)
+	state.set("zone", zone)
+	state.set("target", target)
+	state.set("aliases", present)
+
+	return state.diags
+}
+
+func resourceAliasesUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+	target := d.Get("target").(string)
+
+	oldRaw, newRaw := d.GetChange("aliases")
+	oldAliases := setToStrings(oldRaw.(*schema.Set).List())
+	newAliases := setToStrings(newRaw.(*schema.Set).List())
+	added, removed := stringSetDiff(oldAliases, newAliases)
+
+	if err := checkZoneQuota(d, c, server, zone, len(added)-len(removed)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// New aliases may still exist with a stale target (drift), so replace rather than create
+	toReplace := added
+	if d.HasChange("target") {
+		toReplace = newAliases
+	}
+	for _, r := range aliasRecords(server, zone, target, toReplace) {
+		if err := replaceRecordValue(c, r); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	for _, r := range aliasRecords(server, zone, "", removed) {
+		if err := deleteStoredRecord(c, r); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId(buildAliasesID(server, zone, newAliases))
+
+	diags := setWriteMetadata(d, m)
+
+	return append(diags, resourceAliasesRead(ctx, d, m)...)
+}
+
+func resourceAliasesDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+
+	for _, r := range aliasRecords(server, zone, "", setToStrings(d.Get("aliases").(*schema.Set).List())) {
+		if err := deleteStoredRecord(c, r); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
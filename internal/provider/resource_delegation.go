@@ -0,0 +1,402 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceDelegationExample is the example configuration shown in the resource documentation
+const resourceDelegationExample = `
+resource "sambadns_delegation" "lab" {
+  server = "dc01.example.com"
+  zone   = "example.com"
+  name   = "lab"
+
+  nameserver {
+    hostname  = "ns1.lab.example.com"
+    addresses = ["10.20.0.53"]
+  }
+  nameserver {
+    hostname = "ns.partner.net"
+  }
+}
+`
+
+func resourceDelegation() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Delegates a subdomain to external nameservers by managing its NS records and the matching glue "+
+			"A/AAAA records in the parent zone. Glue is required for in-bailiwick nameservers and rejected for others.",
+			resourceDelegationExample, "terraform import sambadns_delegation.lab \"dc01.example.com/example.com/lab\""),
+
+		CreateContext: wrapCRUD(resourceDelegationCreate),
+		ReadContext:   wrapCRUD(resourceDelegationRead),
+		UpdateContext: wrapCRUD(resourceDelegationUpdate),
+		DeleteContext: wrapCRUD(resourceDelegationDelete),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		CustomizeDiff: customdiff.All(
+			validateDelegationGlue,
+			validateDelegationPolicy,
+			estimateOperations(delegationOperations),
+		),
+
+		Schema: withPlanEstimate(map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Parent zone (e.g., example.com).",
+			},
+			"name": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateFunc:     validateDNSName,
+				DiffSuppressFunc: suppressEquivalentName,
+				Description:      "Delegated label relative to the parent zone (e.g., `lab` for lab.example.com).",
+			},
+			"nameserver": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Description: "Nameservers the subdomain is delegated to.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"hostname": {
+							Type:        schema.TypeString,
+							Required:    true,
+							StateFunc:   func(v interface{}) string { return normalizeHostname(v.(string)) },
+							Description: "Nameserver hostname (e.g., ns1.lab.example.com).",
+						},
+						"addresses": {
+							Type:        schema.TypeSet,
+							Optional:    true,
+							Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateAddressLiteral},
+							Description: "Glue addresses. Required when the hostname is inside the delegated subdomain, not allowed otherwise.",
+						},
+					},
+				},
+			},
+			"max_zone_records": maxZoneRecordsSchema(),
+			"profile":          profileSchema(),
+			"credentials":      credentialsSchema(),
+			"retry":            resourceRetrySchema(),
+			"write_metadata":   writeMetadataSchema(),
+		}),
+	}
+}
+
+// delegatedZone returns the FQDN of the delegated subdomain
+func delegatedZone(zone, name string) string {
+	return name + "." + trimRoot(zone)
+}
+
+// validateDelegationGlue checks glue against bailiwick at plan time
+func validateDelegationGlue(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("nameserver") || !d.NewValueKnown("name") || !d.NewValueKnown("zone") {
+		return nil
+	}
+	child := delegatedZone(d.Get("zone").(string), d.Get("name").(string))
+
+	for _, raw := range d.Get("nameserver").(*schema.Set).List() {
+		ns := raw.(map[string]interface{})
+		host := normalizeHostname(ns["hostname"].(string))
+		addresses := ns["addresses"].(*schema.Set).Len()
+		_, inBailiwick := relativeName(host, child)
+
+		switch {
+		case inBailiwick && addresses == 0:
+			return fmt.Errorf("nameserver %s is inside %s and needs glue addresses", host, child)
+		case !inBailiwick && addresses > 0:
+			return fmt.Errorf("nameserver %s is outside %s; glue addresses are only allowed for in-bailiwick nameservers", host, child)
+		}
+	}
+	return nil
+}
+
+// validateDelegationPolicy applies the provider naming and address policies at plan time
+// The delegated name and every glue record are checked the way sambadns_record checks a record
+func validateDelegationPolicy(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if m == nil || !d.NewValueKnown("name") || !d.NewValueKnown("zone") {
+		return nil
+	}
+	a := m.(*apiClient)
+	if err := a.checkNamePolicy(d.Get("name").(string)); err != nil {
+		return err
+	}
+	if !d.NewValueKnown("nameserver") {
+		return nil
+	}
+	zone := d.Get("zone").(string)
+	for _, raw := range d.Get("nameserver").(*schema.Set).List() {
+		ns := raw.(map[string]interface{})
+		addresses := setToStrings(ns["addresses"].(*schema.Set).List())
+		if len(addresses) == 0 {
+			continue
+		}
+		host := normalizeHostname(ns["hostname"].(string))
+		if glueName, ok := relativeName(host, zone); ok {
+			if err := a.checkNamePolicy(glueName); err != nil {
+				return fmt.Errorf("glue for nameserver %s: %w", host, err)
+			}
+		}
+		for _, address := range addresses {
+			if err := checkAllowedAddress(a.allowedCIDRs, addressRecordType(address), address); err != nil {
+				return fmt.Errorf("glue for nameserver %s: %w", host, err)
+			}
+		}
+	}
+	return nil
+}
+
+// delegationRecords builds the NS and glue records for a set of nameservers
+func delegationRecords(server, zone, name string, nameservers []interface{}) ([]DNSRecord, error) {
+	var records []DNSRecord
+	for _, raw := range nameservers {
+		ns := raw.(map[string]interface{})
+		host := normalizeHostname(ns["hostname"].(string))
+		records = append(records, DNSRecord{Server: server, Zone: zone, Name: name, Type: "NS", Value: host})
+
+		addresses := setToStrings(ns["addresses"].(*schema.Set).List())
+		if len(addresses) == 0 {
+			continue
+		}
+		glueName, ok := relativeName(host, zone)
+		if !ok {
+			return nil, fmt.Errorf("glue for %s cannot be created outside zone %s", host, zone)
+		}
+		for _, address := range addresses {
+			records = append(records, DNSRecord{
+				Server: server,
+				Zone:   zone,
+				Name:   glueName,
+				Type:   addressRecordType(address),
+				Value:  address,
+			})
+		}
+	}
+	return records, nil
+}
+
+// recordKey identifies a record by name, type and normalized value
+func recordKey(r DNSRecord) string {
+	return fmt.Sprintf("%s/%s/%s", strings.ToLower(r.Name), r.Type, normalizeIPv6(strings.ToLower(r.Value)))
+}
+
+// diffRecords returns the records only in want and only in have
+func diffRecords(have, want []DNSRecord) (adds, removes []DNSRecord) {
+	haveKeys := make(map[string]bool, len(have))
+	for _, r := range have {
+		haveKeys[recordKey(r)] = true
+	}
+	wantKeys := make(map[string]bool, len(want))
+	for _, r := range want {
+		wantKeys[recordKey(r)] = true
+		if !haveKeys[recordKey(r)] {
+			adds = append(adds, r)
+		}
+	}
+	for _, r := range have {
+		if !wantKeys[recordKey(r)] {
+			removes = append(removes, r)
+		}
+	}
+	return adds, removes
+}
+
+// delegationOperations counts the NS and glue records the planned change adds or removes
+func delegationOperations(d *schema.ResourceDiff) (int, bool) {
+	if !d.NewValueKnown("nameserver") {
+		return 0, false
+	}
+	server, zone, name := d.Get("dns_server").(string), d.Get("zone").(string), d.Get("name").(string)
+	oldRaw, newRaw := d.GetChange("nameserver")
+	have, err := delegationRecords(server, zone, name, oldRaw.(*schema.Set).List())
+	if err != nil {
+		return 0, false
+	}
+	want, err := delegationRecords(server, zone, name, newRaw.(*schema.Set).List())
+	if err != nil {
+		return 0, false
+	}
+	adds, removes := diffRecords(have, want)
+	return len(adds) + len(removes), true
+}
+
+func resourceDelegationCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+	name := d.Get("name").(string)
+
+	records, err := delegationRecords(server, zone, name, d.Get("nameserver").(*schema.Set).List())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := checkZoneQuota(d, c, server, zone, len(records)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// Glue first, so the NS records never point at unresolvable in-bailiwick names
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Type != "NS" && records[j].Type == "NS" })
+	if err := applyRecordChanges(c, records, nil); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(buildNameID(server, zone, name))
+
+	diags := setWriteMetadata(d, m)
+
+	return append(diags, resourceDelegationRead(ctx, d, m)...)
+}
+
+func resourceDelegationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server, zone, name, err := parseNameID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	records, err := c.QueryName(server, zone, name)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to query delegation: %w", err))
+	}
+
+	// Keep the configured spelling of glue addresses the server reports in another form
+	configured := make(map[string]string)
+	for _, raw := range d.Get("nameserver").(*schema.Set).List() {
+		for _, address := range setToStrings(raw.(map[string]interface{})["addresses"].(*schema.Set).List()) {
+			configured[normalizeIPv6(address)] = address
+		}
+	}
+
+	child := delegatedZone(zone, name)
+	var nameservers []interface{}
+	for _, r := range records {
+		if r.Type != "NS" {
+			continue
+		}
+		host := normalizeHostname(r.Value)
+
+		var addresses []interface{}
+		if _, inBailiwick := relativeName(host, child); inBailiwick {
+			glueName, _ := relativeName(host, zone)
+			glue, err := c.QueryName(server, zone, glueName)
+			if err != nil {
+				return diag.FromErr(fmt.Errorf("failed to query glue for %s: %w", host, err))
+			}
+			for _, g := range glue {
+				if g.Type != "A" && g.Type != "AAAA" {
+					continue
+				}
+				if address, ok := configured[normalizeIPv6(g.Value)]; ok {
+					addresses = append(addresses, address)
+				} else {
+					addresses = append(addresses, g.Value)
+				}
+			}
+		}
+
+		nameservers = append(nameservers, map[string]interface{}{
+			"hostname":  host,
+			"addresses": schema.NewSet(schema.HashString, addresses),
+		})
+	}
+
+	if len(nameservers) == 0 {
+		d.SetId("")
+		return nil
+	}
+
+	state := newStateSetter(d)
+	state.set("dns_server", server)
+	state.set("zone", zone)
+	state.set("name", name)
+	state.set("nameserver", nameservers)
+
+	return state.diags
+}
+
+func resourceDelegationUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.HasChange("nameserver") {
+		server := d.Get("dns_server").(string)
+		zone := d.Get("zone").(string)
+		name := d.Get("name").(string)
+
+		oldRaw, newRaw := d.GetChange("nameserver")
+		have, err := delegationRecords(server, zone, name, oldRaw.(*schema.Set).List())
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		want, err := delegationRecords(server, zone, name, newRaw.(*schema.Set).List())
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		adds, removes := diffRecords(have, want)
+		if err := checkZoneQuota(d, c, server, zone, len(adds)-len(removes)); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := applyRecordChanges(c, adds, removes); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	diags := setWriteMetadata(d, m)
+
+	return append(diags, resourceDelegationRead(ctx, d, m)...)
+}
+
+func resourceDelegationDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+	name := d.Get("name").(string)
+
+	records, err := delegationRecords(server, zone, name, d.Get("nameserver").(*schema.Set).List())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// NS records first, then the glue they relied on
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Type == "NS" && records[j].Type != "NS" })
+	if err := applyRecordChanges(c, nil, records); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,252 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// resourceMXSetExample is the example configuration shown in the resource documentation
+const resourceMXSetExample = `
+resource "sambadns_mx_set" "mail" {
+  server = "dc01.example.com"
+  zone   = "example.com"
+
+  mx {
+    preference = 10
+    exchange   = "mx1.example.com."
+  }
+  mx {
+    preference = 20
+    exchange   = "mx2.example.com"
+  }
+}
+`
+
+func resourceMXSet() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Manages all MX records of a name as one normalized set. Entries are compared without regard "+
+			"to order, exchange case or trailing dots, so mail domains stop showing false diffs.",
+			resourceMXSetExample, "terraform import sambadns_mx_set.mail \"dc01.example.com/example.com/@\""),
+
+		CreateContext: wrapCRUD(resourceMXSetCreate),
+		ReadContext:   wrapCRUD(resourceMXSetRead),
+		UpdateContext: wrapCRUD(resourceMXSetUpdate),
+		DeleteContext: wrapCRUD(resourceMXSetDelete),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		CustomizeDiff: customdiff.All(
+			validateNamePolicy,
+			estimateOperations(func(d *schema.ResourceDiff) (int, bool) { return setChangeCount(d, "mx") }),
+		),
+
+		Schema: withPlanEstimate(map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS zone name (e.g., example.com).",
+			},
+			"name": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				Default:          "@",
+				ValidateFunc:     validateDNSName,
+				DiffSuppressFunc: suppressEquivalentName,
+				Description:      "Record name. Defaults to the zone apex (`@`).",
+			},
+			"mx": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Set:         mxHash,
+				Description: "MX entries.",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"preference": {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntBetween(0, 65535),
+							Description:  "Preference; lower values are tried first.",
+						},
+						"exchange": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validateNoTemplatePlaceholders,
+							DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+								return normalizeHostname(old) == normalizeHostname(new)
+							},
+							Description: "Mail server hostname (e.g., mail.example.com).",
+						},
+					},
+				},
+			},
+			"max_zone_records": maxZoneRecordsSchema(),
+			"profile":          profileSchema(),
+			"credentials":      credentialsSchema(),
+			"retry":            resourceRetrySchema(),
+			"write_metadata":   writeMetadataSchema(),
+		}),
+	}
+}
+
+// mxHash hashes an MX entry on its normalized form, making the set insensitive to case and trailing dots
+func mxHash(v interface{}) int {
+	entry := v.(map[string]interface{})
+	return schema.HashString(fmt.Sprintf("%d %s", entry["preference"].(int), normalizeHostname(entry["exchange"].(string))))
+}
+
+// mxRecords builds the samba-tool MX records ("hostname preference") for a set of entries
+func mxRecords(server, zone, name string, entries []interface{}) []DNSRecord {
+	records := make([]DNSRecord, 0, len(entries))
+	for _, e := range entries {
+		entry := e.(map[string]interface{})
+		records = append(records, DNSRecord{
+			Server: server,
+			Zone:   zone,
+			Name:   name,
+			Type:   "MX",
+			Value:  fmt.Sprintf("%s %d", normalizeHostname(entry["exchange"].(string)), entry["preference"].(int)),
+		})
+	}
+	return records
+}
+
+func resourceMXSetCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+	name := d.Get("name").(string)
+	records := mxRecords(server, zone, name, d.Get("mx").(*schema.Set).List())
+
+	if err := checkZoneQuota(d, c, server, zone, len(records)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := applyRecordChanges(c, records, nil); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(buildNameID(server, zone, name))
+
+	diags := setWriteMetadata(d, m)
+
+	return append(diags, resourceMXSetRead(ctx, d, m)...)
+}
+
+func resourceMXSetRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server, zone, name, err := parseNameID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	records, err := c.QueryName(server, zone, name)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to query MX records: %w", err))
+	}
+
+	var entries []interface{}
+	for _, r := range records {
+		if r.Type != "MX" {
+			continue
+		}
+		// parseRecordLine returns MX values as "hostname preference"
+		exchange, preference, found := strings.Cut(r.Value, " ")
+		if !found {
+			return diag.Errorf("unexpected MX value from server: %q", r.Value)
+		}
+		pref, err := strconv.Atoi(preference)
+		if err != nil {
+			return diag.Errorf("unexpected MX preference from server: %q", r.Value)
+		}
+		entries = append(entries, map[string]interface{}{
+			"preference": pref,
+			"exchange":   exchange,
+		})
+	}
+
+	if len(entries) == 0 {
+		d.SetId("")
+		return nil
+	}
+
+	state := newStateSetter(d)
+	state.set("dns_server", server)
+	state.set("zone", zone)
+	state.set("name", name)
+	state.set("mx", schema.NewSet(mxHash, entries))
+
+	return state.diags
+}
+
+func resourceMXSetUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.HasChange("mx") {
+		server := d.Get("dns_server").(string)
+		zone := d.Get("zone").(string)
+		name := d.Get("name").(string)
+
+		oldRaw, newRaw := d.GetChange("mx")
+		oldSet := oldRaw.(*schema.Set)
+		newSet := newRaw.(*schema.Set)
+
+		adds := mxRecords(server, zone, name, newSet.Difference(oldSet).List())
+		removes := mxRecords(server, zone, name, oldSet.Difference(newSet).List())
+		if err := checkZoneQuota(d, c, server, zone, len(adds)-len(removes)); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := applyRecordChanges(c, adds, removes); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	diags := setWriteMetadata(d, m)
+
+	return append(diags, resourceMXSetRead(ctx, d, m)...)
+}
+
+func resourceMXSetDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+	name := d.Get("name").(string)
+
+	if err := applyRecordChanges(c, nil, mxRecords(server, zone, name, d.Get("mx").(*schema.Set).List())); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -4,69 +4,92 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
-// normalizeIPv6 expands an IPv6 address to its full form for comparison
-// e.g., "2001:db8::1" -> "2001:0db8:0000:0000:0000:0000:0000:0001"
-func normalizeIPv6(ip string) string {
-	parsed := net.ParseIP(ip)
-	if parsed == nil {
-		return ip // Return as-is if not a valid IP
-	}
-	// Check if it's IPv6 (To16 returns 16 bytes for both, but To4 returns nil for IPv6)
-	if parsed.To4() != nil {
-		return ip // It's IPv4, return as-is
-	}
-	// Expand to full IPv6 format
-	ipv6 := parsed.To16()
-	if ipv6 == nil {
-		return ip
-	}
-	// Format as 8 groups of 4 hex digits
-	return fmt.Sprintf("%02x%02x:%02x%02x:%02x%02x:%02x%02x:%02x%02x:%02x%02x:%02x%02x:%02x%02x",
-		ipv6[0], ipv6[1], ipv6[2], ipv6[3],
-		ipv6[4], ipv6[5], ipv6[6], ipv6[7],
-		ipv6[8], ipv6[9], ipv6[10], ipv6[11],
-		ipv6[12], ipv6[13], ipv6[14], ipv6[15])
-}
-
 // suppressValueDiff handles format differences between config and DNS server response
 // - AAAA: IPv6 short form vs expanded form
-// - CNAME: with/without trailing dot (FQDN format)
+// - CNAME, NS, PTR, MX and SRV hosts: with/without trailing dot (FQDN format)
 func suppressValueDiff(k, old, new string, d *schema.ResourceData) bool {
-	recordType := strings.ToUpper(d.Get("type").(string))
-	
-	switch recordType {
-	case "AAAA":
-		return normalizeIPv6(old) == normalizeIPv6(new)
-	case "CNAME":
-		// Normalize trailing dots - DNS returns FQDN with dot, users often omit it
-		oldNorm := strings.TrimSuffix(old, ".")
-		newNorm := strings.TrimSuffix(new, ".")
-		return oldNorm == newNorm
-	default:
-		return false
+	return recordValuesEqual(d.Get("type").(string), old, new)
+}
+
+// validateNoTemplatePlaceholders rejects values containing "${" or "%{", which almost
+// always mean an interpolation went wrong (e.g., a heredoc or a templatefile escape)
+func validateNoTemplatePlaceholders(v interface{}, k string) (warnings []string, errs []error) {
+	value := v.(string)
+	for _, marker := range []string{"${", "%{"} {
+		if strings.Contains(value, marker) {
+			errs = append(errs, fmt.Errorf("%q contains an unresolved template placeholder %q in %q; check the interpolation that produced it", k, marker, value))
+		}
 	}
+	return warnings, errs
+}
+
+// suppressZeroTTLDiff treats a configured TTL of 0 as "use the zone default",
+// so whatever TTL the server reports is accepted
+func suppressZeroTTLDiff(k, old, new string, d *schema.ResourceData) bool {
+	return new == "0"
 }
 
+// resourceRecordExample is the example configuration shown in the resource documentation
+const resourceRecordExample = `
+resource "sambadns_record" "web" {
+  server   = "dc01.example.com"
+  zone     = "example.com"
+  name     = "web"
+  type     = "A"
+  value    = "10.0.0.5"
+  ptr_zone = "0.10.in-addr.arpa"
+}
+
+resource "sambadns_record" "apps_wildcard" {
+  server = "dc01.example.com"
+  fqdn   = "*.apps.example.com"
+  type   = "CNAME"
+  value  = "ingress.example.com"
+}
+`
+
 func resourceRecord() *schema.Resource {
 	return &schema.Resource{
-		Description: "Manages a DNS record via samba-tool (MS-DNSP RPC). Supports wildcard records.",
+		Description: docDescription("Manages a DNS record via samba-tool (MS-DNSP RPC). Supports wildcard records.",
+			resourceRecordExample, "terraform import sambadns_record.web \"dc01.example.com/example.com/web/A\""),
 
-		CreateContext: resourceRecordCreate,
-		ReadContext:   resourceRecordRead,
-		UpdateContext: resourceRecordUpdate,
-		DeleteContext: resourceRecordDelete,
+		CreateContext: wrapCRUD(resourceRecordCreate),
+		ReadContext:   wrapCRUD(resourceRecordRefresh),
+		UpdateContext: wrapCRUD(resourceRecordUpdate),
+		DeleteContext: wrapCRUD(resourceRecordDelete),
 
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
 		},
 
+		CustomizeDiff: customdiff.All(
+			resolveRecordFQDN,
+			checkZoneSuffix,
+			validateAValue,
+			validateTXTValue,
+			validateVerifiableName,
+			validateAllowedCIDRs,
+			validateNamePolicy,
+			validatePTRZone,
+			validateRecordFlags,
+			validateTTL,
+			planTTLSource,
+			summarizeChange("sambadns_record", "value", "name"),
+			customdiff.ComputedIf("canonical_value", func(ctx context.Context, d *schema.ResourceDiff, m interface{}) bool {
+				return d.HasChange("value")
+			}),
+		),
+
 		Schema: map[string]*schema.Schema{
 			"dns_server": {
 				Type:        schema.TypeString,
@@ -76,58 +99,638 @@ func resourceRecord() *schema.Resource {
 			},
 			"zone": {
 				Type:        schema.TypeString,
-				Required:    true,
+				Optional:    true,
+				Computed:    true,
 				ForceNew:    true,
-				Description: "DNS zone name (e.g., example.com).",
+				Description: "DNS zone name (e.g., example.com). Required unless `fqdn` is set.",
 			},
 			"name": {
-				Type:        schema.TypeString,
-				Required:    true,
-				ForceNew:    true,
-				Description: "Record name. Use * for wildcards (e.g., *.myapp, *.sub.myapp).",
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateFunc:     validateDNSName,
+				DiffSuppressFunc: suppressEquivalentName,
+				Description: "Record name. Use * for wildcards (e.g., *.myapp, *.sub.myapp). Required unless `fqdn` is set. Changing it renames the record in place: the new name is written before the old one is removed. " +
+					"Labels use RFC 1035 escapes: `\\.` for a dot inside a label, `\\DDD` for any byte.",
+			},
+			"fqdn": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{"zone", "name"},
+				Description: "Fully qualified record name (e.g., web01.apps.example.com), instead of `zone` and `name`. " +
+					"The longest matching zone hosted on `server` is chosen at plan time and exposed as `zone`.",
+			},
+			"strip_zone_suffix": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When `name` ends with the zone (e.g., `web01.example.com` in zone `example.com`), manage it as the relative name `web01` instead of failing the plan.",
 			},
 			"type": {
-				Type:     schema.TypeString,
-				Required: true,
-				ForceNew: true,
-				ValidateFunc: validation.StringInSlice([]string{
-					"A", "AAAA", "CNAME", "TXT", "MX", "PTR", "SRV", "NS",
-				}, true),
-				StateFunc:   func(v interface{}) string { return strings.ToUpper(v.(string)) },
-				Description: "Record type (A, AAAA, CNAME, TXT, MX, PTR, SRV, NS).",
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(supportedRecordTypes, true),
+				StateFunc:    func(v interface{}) string { return strings.ToUpper(v.(string)) },
+				Description:  "Record type (A, AAAA, CNAME, TXT, MX, PTR, SRV, NS).",
 			},
 			"value": {
 				Type:             schema.TypeString,
 				Required:         true,
 				DiffSuppressFunc: suppressValueDiff,
+				ValidateFunc:     validateNoTemplatePlaceholders,
 				Description:      "Record value. For A: IP address, CNAME: FQDN, MX: priority hostname, etc.",
 			},
+			"canonical_value": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "The value as the server stores it, in the normalized form `value` is compared in (e.g., expanded IPv6, lowercase hostnames without the trailing dot). Useful when a diff is suppressed unexpectedly.",
+			},
 			"ttl": {
-				Type:        schema.TypeInt,
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Computed:         true,
+				ValidateFunc:     validation.IntBetween(0, maxTTL),
+				DiffSuppressFunc: suppressZeroTTLDiff,
+				Description: "Time to live in seconds (0-2147483647). samba-tool cannot write TTLs, so only `0` or leaving it out is supported: " +
+					"records get the server's TTL. A non-zero value is only accepted when it matches the TTL the server already stores, e.g. on an imported record.",
+			},
+			"ttl_source": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Where the stored `ttl` comes from: `zone-default` when it is the zone's default TTL (the SOA minimum TTL), `explicit` when the server stores a different TTL on the record.",
+			},
+			"allowed_cidrs": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.IsCIDR},
+				Description: "Address ranges an A/AAAA value must fall within. Overrides the provider-level `allowed_cidrs`.",
+			},
+			"ptr_zone": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "For A and AAAA records, reverse zone (e.g., `0.10.in-addr.arpa`) to keep the matching PTR record in. The record and its PTR are written as a unit and rolled back together when either write fails.",
+			},
+			"immutable": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Trust state instead of querying the record on refresh, for large workspaces of records that never change outside Terraform. The record is still read back after every create or update. Check such records for drift with the `sambadns_drift` data source.",
+			},
+			"rank": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.StringInSlice(recordRankNames, false),
+				Description: "Rank of the record: `zone` for zone data, `glue` or `ns-glue` for glue at a delegation, `root-hint` for root hints. " +
+					"Left out, the rank the server stores is read back. samba-tool writes every record as `zone` data, so other ranks need a backend that can write record flags (see the `record_flags_write` capability).",
+			},
+			"suppress_notify": {
+				Type:        schema.TypeBool,
 				Optional:    true,
+				Default:     false,
+				Description: "Set DNS_RPC_FLAG_SUPPRESS_NOTIFY on the record, so writing it does not notify secondaries. Needs a backend that can write record flags.",
+			},
+			"flags": {
+				Type:        schema.TypeList,
 				Computed:    true,
-				Description: "Time to live in seconds. Defaults to zone default (typically 3600).",
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "The DNS_RPC_FLAG_* flags set on the stored record (e.g., `DNS_RPC_FLAG_ZONE_ROOT`).",
+			},
+			"check_zone_placement": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Before creating, confirm with zoneinfo that `server` hosts the zone as a writable primary. A missing zone fails with guidance, a non-primary copy produces a warning.",
+			},
+			"warn_if_referenced": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Before deleting, scan the zone for CNAMEs pointing at this name and warn, listing them.",
+			},
+			"block_if_referenced": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Before deleting, scan the zone for CNAMEs pointing at this name and refuse to delete while any exist.",
+			},
+			"validate_target": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "For CNAME, MX, SRV and NS records, check before writing that the target name resolves, through the DC or the local resolver.",
+			},
+			"verify_resolution": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "After create or update, query the DC over DNS (and every `verify_resolvers` entry) and fail unless the answer contains the new value.",
+			},
+			"verify_resolvers": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Additional resolver addresses to check when `verify_resolution` is enabled (e.g., `10.0.0.53`).",
+			},
+			"verify_timeout": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "30s",
+				ValidateFunc: validateDuration,
+				Description:  "How long each resolver may take to serve the new value.",
+			},
+			"canary_resolvers": {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Recursive resolvers (e.g., `8.8.8.8` or branch office forwarders) to watch after create or update, for coordinated cutovers. The apply waits until each serves the new value, or until the old answer's cached TTL has elapsed, and warns about resolvers that never do.",
 			},
+			"canary_max_wait": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "10m",
+				ValidateFunc: validateDuration,
+				Description:  "Upper bound on the canary wait, for records whose TTL is longer than an apply should block.",
+			},
+			"profile":        profileSchema(),
+			"credentials":    credentialsSchema(),
+			"retry":          resourceRetrySchema(),
+			"write_metadata": writeMetadataSchema(),
 		},
 	}
 }
 
+// supportedRecordTypes lists the record types samba-tool can manage
+var supportedRecordTypes = []string{"A", "AAAA", "CNAME", "TXT", "MX", "PTR", "SRV", "NS"}
+
+// resolveRecordFQDN fills in zone and name from a configured fqdn
+// The server's zone list is consulted at plan time, so the plan shows the chosen zone
+func resolveRecordFQDN(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	// fqdn is Computed, so an unset fqdn reads as its state value: only new
+	// resources and configured changes need a lookup
+	if !d.NewValueKnown("fqdn") || !d.NewValueKnown("dns_server") || (d.Id() != "" && !d.HasChange("fqdn")) {
+		return nil
+	}
+	if d.Get("fqdn").(string) == "" {
+		// zone and name are unknown rather than empty when left out, so the configuration is checked
+		if raw := d.GetRawConfig(); !raw.IsNull() && (raw.GetAttr("zone").IsNull() || raw.GetAttr("name").IsNull()) {
+			return fmt.Errorf("either fqdn, or both zone and name, must be set")
+		}
+		return nil
+	}
+	if m == nil {
+		return nil
+	}
+
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return err
+	}
+	server := d.Get("dns_server").(string)
+	fqdn := d.Get("fqdn").(string)
+
+	zones, err := c.ListZones(server)
+	if err != nil {
+		return fmt.Errorf("failed to list zones to place %s: %w", fqdn, err)
+	}
+	zone, name, ok := zoneForFQDN(zones, fqdn)
+	if !ok {
+		return fmt.Errorf("no zone hosted on %s contains %s; set zone and name explicitly", server, fqdn)
+	}
+
+	if err := d.SetNew("zone", zone); err != nil {
+		return err
+	}
+	return d.SetNew("name", name)
+}
+
+// checkZoneSuffix catches names written as FQDNs, which samba-tool would store as
+// web01.example.com.example.com; they fail the plan unless strip_zone_suffix is set
+func checkZoneSuffix(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("name") || !d.NewValueKnown("zone") || !d.NewValueKnown("strip_zone_suffix") {
+		return nil
+	}
+	name := d.Get("name").(string)
+	zone := d.Get("zone").(string)
+	if name == "" || zone == "" {
+		return nil
+	}
+
+	relative, ok := relativeName(name, zone)
+	if !ok {
+		if hasRoot(name) {
+			return fmt.Errorf("name %q ends with a dot but is not inside zone %s; names are relative to the zone", name, zone)
+		}
+		return nil
+	}
+	if !d.Get("strip_zone_suffix").(bool) {
+		return fmt.Errorf("name %q already ends with zone %s and would be created as %s.%s; use name = %q, or set strip_zone_suffix = true",
+			name, zone, trimRoot(name), trimRoot(zone), relative)
+	}
+	return d.SetNew("name", relative)
+}
+
+// zoneForFQDN picks the longest zone containing fqdn and returns the name relative to it
+// The longest match wins, so web01.apps.example.com lands in apps.example.com when both zones exist
+func zoneForFQDN(zones []string, fqdn string) (zone, name string, ok bool) {
+	for _, z := range zones {
+		n, inside := relativeName(fqdn, z)
+		if inside && len(z) > len(zone) {
+			zone, name, ok = z, n, true
+		}
+	}
+	return zone, name, ok
+}
+
+// joinFQDN is the inverse of relativeName
+func joinFQDN(name, zone string) string {
+	zone = trimRoot(zone)
+	if name == "@" || name == "" {
+		return zone
+	}
+	return name + "." + zone
+}
+
+// validateAllowedCIDRs fails the plan when an A/AAAA value falls outside the approved ranges
+func validateAllowedCIDRs(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	recordType := strings.ToUpper(d.Get("type").(string))
+	if recordType != "A" && recordType != "AAAA" {
+		return nil
+	}
+	if !d.NewValueKnown("value") || !d.NewValueKnown("allowed_cidrs") {
+		return nil
+	}
+
+	allowed, err := parseCIDRs(d.Get("allowed_cidrs").([]interface{}))
+	if err != nil {
+		return err
+	}
+	if len(allowed) == 0 && m != nil {
+		allowed = m.(*apiClient).allowedCIDRs
+	}
+
+	return checkAllowedAddress(allowed, recordType, d.Get("value").(string))
+}
+
+// checkAllowedAddress verifies that an A/AAAA value lies within one of the allowed networks
+func checkAllowedAddress(allowed []*net.IPNet, recordType, value string) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return fmt.Errorf("%s record value %q is not an IP address", recordType, value)
+	}
+	for _, n := range allowed {
+		if n.Contains(ip) {
+			return nil
+		}
+	}
+
+	ranges := make([]string, len(allowed))
+	for i, n := range allowed {
+		ranges[i] = n.String()
+	}
+	return fmt.Errorf("%s record value %s is outside the allowed ranges (%s)", recordType, value, strings.Join(ranges, ", "))
+}
+
+// checkIPv4Literal rejects IPv4 spellings that tools disagree on
+// inet_aton reads "010.1.1.5" as octal 8.1.1.5 and accepts "10.1" or "0x0a.1.1.5", while other
+// parsers read decimal or refuse them, so only plain dotted decimal is accepted
+func checkIPv4Literal(value string) error {
+	if net.ParseIP(value) != nil {
+		return nil
+	}
+	octets := strings.Split(value, ".")
+	if len(octets) != 4 {
+		return fmt.Errorf("%q is not a dotted-decimal IPv4 address; write all four octets in decimal (e.g., 10.0.0.5)", value)
+	}
+	decimal := make([]string, 4)
+	octal := make([]string, 4)
+	for i, octet := range octets {
+		d, errD := strconv.ParseUint(octet, 10, 8)
+		o, errO := strconv.ParseUint(octet, 8, 8)
+		if octet == "" || errD != nil {
+			return fmt.Errorf("%q is not a dotted-decimal IPv4 address; write all four octets in decimal (e.g., 10.0.0.5)", value)
+		}
+		decimal[i] = strconv.FormatUint(d, 10)
+		octal[i] = decimal[i]
+		if errO == nil {
+			octal[i] = strconv.FormatUint(o, 10)
+		}
+	}
+	return fmt.Errorf("%q has octets with leading zeros, which some tools read as octal (%s) and others as decimal (%s); write it as %s",
+		value, strings.Join(octal, "."), strings.Join(decimal, "."), strings.Join(decimal, "."))
+}
+
+// checkIPv6Literal rejects AAAA values that are not IPv6 addresses, including IPv4 addresses
+func checkIPv6Literal(value string) error {
+	if !strings.Contains(value, ":") || net.ParseIP(value) == nil {
+		return fmt.Errorf("%q is not an IPv6 address (e.g., 2001:db8::10)", value)
+	}
+	return nil
+}
+
+// checkRecordValue applies the plan-time value checks of sambadns_record to one value, for resources
+// holding several: address literals and the provider allowed_cidrs for A and AAAA, TXT bytes for TXT
+func checkRecordValue(m interface{}, recordType, value string) error {
+	recordType = strings.ToUpper(recordType)
+	var err error
+	switch recordType {
+	case "A":
+		err = checkIPv4Literal(value)
+	case "AAAA":
+		err = checkIPv6Literal(value)
+	case "TXT":
+		return validateTXTBytes(value)
+	default:
+		return nil
+	}
+	if err != nil || m == nil {
+		return err
+	}
+	return checkAllowedAddress(m.(*apiClient).allowedCIDRs, recordType, value)
+}
+
+// validateAddressLiteral is a schema ValidateFunc for IP addresses that also explains ambiguous IPv4 spellings
+func validateAddressLiteral(v interface{}, k string) (warnings []string, errs []error) {
+	value := v.(string)
+	if strings.Contains(value, ":") {
+		if net.ParseIP(value) == nil {
+			errs = append(errs, fmt.Errorf("%q is not a valid IP address: %q", k, value))
+		}
+		return warnings, errs
+	}
+	if err := checkIPv4Literal(value); err != nil {
+		errs = append(errs, fmt.Errorf("%q: %w", k, err))
+	}
+	return warnings, errs
+}
+
+// validateAValue fails the plan when an A record value is not dotted decimal
+// The check is a CustomizeDiff because whether a value is an address depends on the type
+func validateAValue(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !strings.EqualFold(d.Get("type").(string), "A") || !d.NewValueKnown("value") {
+		return nil
+	}
+	return checkIPv4Literal(d.Get("value").(string))
+}
+
+// validateTXTValue rejects TXT values samba-tool cannot be given, such as NUL bytes
+func validateTXTValue(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !strings.EqualFold(d.Get("type").(string), "TXT") || !d.NewValueKnown("value") {
+		return nil
+	}
+	return validateTXTBytes(d.Get("value").(string))
+}
+
+// validateRecordFlags fails the plan when it sets a rank or suppress_notify the backend cannot write
+// Ranks read back from the server are not written again, so imported glue plans cleanly
+func validateRecordFlags(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if m == nil || !d.NewValueKnown("rank") || !d.NewValueKnown("dns_server") {
+		return nil
+	}
+	oldRank, newRank := d.GetChange("rank")
+	rankWrite := newRank.(string) != "" && newRank != oldRank && !(oldRank.(string) == "" && newRank.(string) == "zone")
+	if !rankWrite && !d.HasChange("suppress_notify") {
+		return nil
+	}
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return err
+	}
+	if c.canWriteRecordFlags() {
+		return nil
+	}
+	return fmt.Errorf("rank and suppress_notify: %w; leave them out to keep the rank the server stores", errRecordFlagsReadOnly)
+}
+
+// applyRecordFlags writes the configured rank and suppress_notify to a record
+// Writes store records as zone data without flags, so nothing is written for the defaults unless force is set
+func applyRecordFlags(c *SambaClient, d *schema.ResourceData, r DNSRecord, force bool) error {
+	rank := d.Get("rank").(string)
+	if rank == "" {
+		rank = "zone"
+	}
+	suppress := d.Get("suppress_notify").(bool)
+	if rank == "zone" && !suppress && !force {
+		return nil
+	}
+	// Plans only write flags on backends that can; a rewritten glue record read from samba-tool becomes
+	// zone data, and the next plan shows the rank change when it is configured
+	if !c.canWriteRecordFlags() {
+		return nil
+	}
+	stored, err := c.QueryRecord(r.Server, r.Zone, r.Name, r.Type)
+	if err != nil {
+		return fmt.Errorf("failed to query record to set its flags: %w", err)
+	}
+	if stored == nil {
+		return fmt.Errorf("record %s %s was not found to set its flags", joinFQDN(r.Name, r.Zone), r.Type)
+	}
+	if stored.Flags&dnsRankMask == recordRanks[rank] && (stored.Flags&dnsRPCFlagSuppressNotify != 0) == suppress {
+		return nil
+	}
+	if err := c.SetRecordFlags(*stored, rank, suppress); err != nil {
+		return fmt.Errorf("failed to set flags of %s %s: %w", joinFQDN(r.Name, r.Zone), r.Type, err)
+	}
+	return nil
+}
+
+// validateVerifiableName fails the plan, rather than the apply after the write, when verify_resolution
+// is set for a name the resolver cannot look up
+func validateVerifiableName(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.Get("verify_resolution").(bool) || !d.NewValueKnown("name") || !d.NewValueKnown("zone") {
+		return nil
+	}
+	if err := checkResolvable(recordFQDN(d.Get("name").(string), d.Get("zone").(string))); err != nil {
+		return fmt.Errorf("verify_resolution: %w; turn it off for this record", err)
+	}
+	return nil
+}
+
+// TTL sources reported in ttl_source
+const (
+	ttlSourceExplicit    = "explicit"
+	ttlSourceZoneDefault = "zone-default"
+)
+
+// validateTTL fails the plan when it sets a TTL the provider would have to write
+// samba-tool dns add takes no TTL, so any other value would silently become the server's and show a diff on every plan
+func validateTTL(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	raw := d.GetRawConfig()
+	if raw.IsNull() {
+		return nil
+	}
+	ttl := raw.GetAttr("ttl")
+	if !ttl.IsKnown() || ttl.IsNull() {
+		return nil
+	}
+	want, _ := ttl.AsBigFloat().Int64()
+	if want == 0 {
+		return nil
+	}
+	// An existing record keeps its TTL unless it is rewritten
+	stored, _ := d.GetChange("ttl")
+	if d.Id() != "" && !d.HasChanges("name", "value", "ptr_zone") && int64(stored.(int)) == want {
+		return nil
+	}
+	if d.Id() == "" {
+		return fmt.Errorf("ttl = %d cannot be applied: samba-tool cannot write record TTLs, so the record would get the server's TTL; leave ttl out or set it to 0", want)
+	}
+	return fmt.Errorf("ttl = %d cannot be applied: samba-tool cannot write record TTLs, and the record is rewritten with the server's TTL (it stores %d now); "+
+		"leave ttl out or set it to 0", want, stored.(int))
+}
+
+// planTTLSource marks ttl_source unknown when the record is rewritten, since the server then picks its TTL
+// The configuration says nothing about it: samba-tool cannot write TTLs
+func planTTLSource(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || !d.HasChanges("name", "value", "ptr_zone") {
+		return nil
+	}
+	return d.SetNewComputed("ttl_source")
+}
+
+// recordTTLSource tells whether the server stores the zone's default TTL on record
+// ok is false when the zone's SOA cannot be read
+func recordTTLSource(c *SambaClient, record *DNSRecord) (source string, ok bool) {
+	if record.Flags&dnsRPCFlagRecordDefaultTTL != 0 {
+		return ttlSourceZoneDefault, true
+	}
+	soa, err := c.QuerySOA(record.Server, record.Zone)
+	if err != nil {
+		return "", false
+	}
+	minTTL, err := strconv.Atoi(soa["minttl"])
+	if err != nil {
+		return "", false
+	}
+	if record.TTL == minTTL {
+		return ttlSourceZoneDefault, true
+	}
+	return ttlSourceExplicit, true
+}
+
+// validateNamePolicy fails the plan when the record name violates the provider naming policy
+func validateNamePolicy(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if m == nil || !d.NewValueKnown("name") {
+		return nil
+	}
+	return m.(*apiClient).checkNamePolicy(d.Get("name").(string))
+}
+
+// verifyRecordResolution checks that the DC and any extra resolvers serve a freshly written record
+func verifyRecordResolution(ctx context.Context, d *schema.ResourceData, r DNSRecord) error {
+	if !d.Get("verify_resolution").(bool) {
+		return nil
+	}
+	timeout, _ := time.ParseDuration(d.Get("verify_timeout").(string))
+
+	resolvers := []string{r.Server}
+	for _, v := range d.Get("verify_resolvers").([]interface{}) {
+		resolvers = append(resolvers, v.(string))
+	}
+
+	fqdn := recordFQDN(r.Name, r.Zone)
+	for _, server := range resolvers {
+		if err := verifyResolution(ctx, resolverFor(server), fqdn, r.Type, r.Value, timeout); err != nil {
+			return fmt.Errorf("record was written but %s does not resolve %s %s to %q: %w", server, fqdn, r.Type, r.Value, err)
+		}
+	}
+	return nil
+}
+
+// watchRecordCanaries waits for the canary resolvers to serve a freshly written record
+// cachedTTL is the TTL of the answer resolvers may still hold; when it is unknown (a new name),
+// the zone's negative caching TTL from the SOA applies
+func watchRecordCanaries(ctx context.Context, d *schema.ResourceData, c *SambaClient, r DNSRecord, cachedTTL int) diag.Diagnostics {
+	resolvers := setToStrings(d.Get("canary_resolvers").([]interface{}))
+	if len(resolvers) == 0 {
+		return nil
+	}
+	maxWait, _ := time.ParseDuration(d.Get("canary_max_wait").(string))
+
+	ttl := time.Duration(cachedTTL) * time.Second
+	if cachedTTL <= 0 {
+		ttl = maxWait
+		if soa, err := c.QuerySOA(r.Server, r.Zone); err == nil {
+			if minTTL, err := strconv.Atoi(soa["minttl"]); err == nil {
+				ttl = time.Duration(minTTL) * time.Second
+			}
+		}
+	}
+
+	return watchCanaries(ctx, resolvers, recordFQDN(r.Name, r.Zone), r.Type, r.Value, ttl, maxWait)
+}
+
+// checkZonePlacement confirms that server hosts zone authoritatively before a write
+// samba-tool otherwise reports a bare WERR_DNS_ERROR_ZONE_DOES_NOT_EXIST when the zone lives in a
+// partition the DC does not replicate (e.g., another domain's DomainDnsZones)
+func checkZonePlacement(c *SambaClient, server, zone string) (diag.Diagnostics, error) {
+	info, err := c.ZoneInfo(server, zone)
+	if err != nil {
+		if isZoneNotExistError(err) {
+			return nil, fmt.Errorf("zone %s is not hosted on %s: its directory partition is probably not replicated to this DC; "+
+				"target a DC in the zone's replication scope, or store the zone in ForestDnsZones", zone, server)
+		}
+		return nil, fmt.Errorf("failed to query zone placement: %w", err)
+	}
+
+	var diags diag.Diagnostics
+	if zoneType := info["dwZoneType"]; zoneType != "" && !strings.Contains(zoneType, "PRIMARY") {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%s does not host %s as a primary zone", server, zone),
+			Detail:   fmt.Sprintf("zoneinfo reports dwZoneType %s (partition %s). Writes may fail or be overwritten by the primary.", zoneType, info["pszDpFqdn"]),
+		})
+	}
+	return diags, nil
+}
+
 // buildID creates a unique resource ID
+// A slash in the zone or name is written as `\047`, so RFC 2317 names such as 0/26 keep the ID parseable
 func buildID(server, zone, name, recordType string) string {
-	return fmt.Sprintf("%s/%s/%s/%s", server, zone, name, strings.ToUpper(recordType))
+	return fmt.Sprintf("%s/%s/%s/%s", server, idComponent(zone), idComponent(name), strings.ToUpper(recordType))
+}
+
+// relativeName returns fqdn relative to zone ("@" for the apex), and false when fqdn is outside zone
+// Names are compared label by label, so an escaped dot never splits a label
+func relativeName(fqdn, zone string) (string, bool) {
+	labels, zoneLabels := splitLabels(fqdn), splitLabels(zone)
+	if len(labels) < len(zoneLabels) || len(zoneLabels) == 0 {
+		return "", false
+	}
+	inside := len(labels) - len(zoneLabels)
+	for i, l := range zoneLabels {
+		if !strings.EqualFold(canonicalLabel(labels[inside+i]), canonicalLabel(l)) {
+			return "", false
+		}
+	}
+	if inside == 0 {
+		return "@", true
+	}
+	return strings.Join(labels[:inside], "."), true
 }
 
 // parseID extracts components from resource ID
+// The type is uppercased, so IDs imported as e.g. dc01/example.com/web/a match the records samba-tool lists
 func parseID(id string) (server, zone, name, recordType string, err error) {
 	parts := strings.SplitN(id, "/", 4)
-	if len(parts) != 4 {
-		return "", "", "", "", fmt.Errorf("invalid ID format: %s (expected server/zone/name/type)", id)
+	if len(parts) != 4 || strings.Contains(parts[3], "/") {
+		return "", "", "", "", fmt.Errorf("invalid ID format: %s (expected server/zone/name/type, with a slash in the zone or name written as \\047)", id)
 	}
-	return parts[0], parts[1], parts[2], parts[3], nil
+	return parts[0], fromIDComponent(parts[1]), fromIDComponent(parts[2]), strings.ToUpper(parts[3]), nil
 }
 
 func resourceRecordCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*apiClient).client
+	a := m.(*apiClient)
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	record := DNSRecord{
 		Server: d.Get("dns_server").(string),
@@ -137,25 +740,79 @@ func resourceRecordCreate(ctx context.Context, d *schema.ResourceData, m interfa
 		Value:  d.Get("value").(string),
 	}
 
-	if err := c.CreateRecord(record); err != nil {
-		return diag.FromErr(fmt.Errorf("failed to create record: %w", err))
+	if d.Get("validate_target").(bool) {
+		if err := validateRecordTarget(ctx, record.Server, record.Type, record.Value); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	var diags diag.Diagnostics
+	if d.Get("check_zone_placement").(bool) {
+		if diags, err = checkZonePlacement(c, record.Server, record.Zone); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	ptr, err := ptrRecord(record, d.Get("ptr_zone").(string))
+	if err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	// The record, its ownership TXT and its PTR are created together or not at all
+	tx := newRecordTransaction(c)
+	if err := tx.create(record); err != nil {
+		return append(diags, diag.FromErr(fmt.Errorf("failed to create record: %w", err))...)
+	}
+	if owner := a.ownerRecord(record); owner != nil {
+		if err := tx.create(*owner); err != nil {
+			return append(diags, diag.FromErr(tx.rollback(fmt.Errorf("failed to create ownership record %s: %w", owner.Name, err)))...)
+		}
+	}
+	if ptr != nil {
+		if err := tx.create(*ptr); err != nil {
+			return append(diags, diag.FromErr(tx.rollback(fmt.Errorf("failed to create PTR record %s: %w", joinFQDN(ptr.Name, ptr.Zone), err)))...)
+		}
 	}
 
 	d.SetId(buildID(record.Server, record.Zone, record.Name, record.Type))
 
+	if err := applyRecordFlags(c, d, record, false); err != nil {
+		return append(diags, diag.FromErr(err)...)
+	}
+
+	if err := verifyRecordResolution(ctx, d, record); err != nil {
+		return diag.FromErr(err)
+	}
+	diags = append(diags, watchRecordCanaries(ctx, d, c, record, 0)...)
+
+	diags = append(diags, setWriteMetadata(d, m)...)
+
 	// Read back to get computed values like TTL
+	return append(diags, resourceRecordRead(ctx, d, m)...)
+}
+
+// resourceRecordRefresh is the Read Terraform runs on refresh; immutable records keep their state without a query
+// Import has no state to trust and always reads
+func resourceRecordRefresh(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	if d.Get("immutable").(bool) && d.Id() != "" && d.Get("value").(string) != "" {
+		return nil
+	}
 	return resourceRecordRead(ctx, d, m)
 }
 
 func resourceRecordRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*apiClient).client
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	server, zone, name, recordType, err := parseID(d.Id())
 	if err != nil {
 		return diag.FromErr(err)
 	}
+	d.SetId(buildID(server, zone, name, recordType))
 
-	record, err := c.QueryRecord(server, zone, name, recordType)
+	record, err := m.(*apiClient).reads.queryRecord(ctx, c, server, zone, name, recordType)
 	if err != nil {
 		return diag.FromErr(fmt.Errorf("failed to query record: %w", err))
 	}
@@ -166,80 +823,212 @@ func resourceRecordRead(ctx context.Context, d *schema.ResourceData, m interface
 		return nil
 	}
 
-	d.Set("dns_server", record.Server)
-	d.Set("zone", record.Zone)
-	d.Set("name", record.Name)
-	d.Set("type", record.Type)
-	d.Set("value", record.Value)
-	d.Set("ttl", record.TTL)
+	state := newStateSetter(d)
+	state.set("dns_server", record.Server)
+	state.set("zone", record.Zone)
+	state.set("name", record.Name)
+	// A configured fqdn is kept as written, so case or a trailing dot never cause a diff
+	if fqdn := d.Get("fqdn").(string); fqdn == "" || !strings.EqualFold(canonicalName(fqdn), canonicalName(joinFQDN(record.Name, record.Zone))) {
+		state.set("fqdn", joinFQDN(record.Name, record.Zone))
+	}
+	state.set("type", record.Type)
+	state.set("value", record.Value)
+	state.set("canonical_value", canonicalValue(record.Type, record.Value))
+	state.set("ttl", record.TTL)
+	state.set("rank", recordRank(record.Flags))
+	state.set("flags", decodeRecordFlags(record.Flags))
+	// An unreadable SOA keeps the last known source rather than failing the read
+	if source, ok := recordTTLSource(c, record); ok {
+		state.set("ttl_source", source)
+	} else if d.Get("ttl_source").(string) == "" {
+		state.set("ttl_source", ttlSourceZoneDefault)
+	}
 
-	return nil
+	return state.diags
 }
 
 func resourceRecordUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*apiClient).client
+	a := m.(*apiClient)
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
-	if d.HasChange("value") {
+	var diags diag.Diagnostics
+	var renamedFrom *DNSRecord
+	if d.HasChanges("name", "value", "ptr_zone") {
 		server := d.Get("dns_server").(string)
 		zone := d.Get("zone").(string)
-		name := d.Get("name").(string)
+		oldName, name := d.GetChange("name")
 		recordType := strings.ToUpper(d.Get("type").(string))
 		newValue := d.Get("value").(string)
+		renamed := d.HasChange("name")
+
+		if d.Get("validate_target").(bool) {
+			if err := validateRecordTarget(ctx, server, recordType, newValue); err != nil {
+				return diag.FromErr(err)
+			}
+		}
 
 		// Query current record to get actual stored value for deletion
-		current, err := c.QueryRecord(server, zone, name, recordType)
+		current, err := c.QueryRecord(server, zone, oldName.(string), recordType)
 		if err != nil {
 			return diag.FromErr(fmt.Errorf("failed to query record for update: %w", err))
 		}
 
-		// Delete old record using actual stored value
-		if current != nil {
-			oldRecord := DNSRecord{
-				Server: server,
-				Zone:   zone,
-				Name:   name,
-				Type:   recordType,
-				Value:  current.Value,
-			}
-			if err := c.DeleteRecord(oldRecord); err != nil {
-				return diag.FromErr(fmt.Errorf("failed to delete old record: %w", err))
-			}
-		}
-
-		// Create new record
 		newRecord := DNSRecord{
 			Server: server,
 			Zone:   zone,
-			Name:   name,
+			Name:   name.(string),
 			Type:   recordType,
 			Value:  newValue,
 		}
-		if err := c.CreateRecord(newRecord); err != nil {
-			return diag.FromErr(fmt.Errorf("failed to create new record: %w", err))
+		oldPTRZone, newPTRZone := d.GetChange("ptr_zone")
+		newPTR, err := ptrRecord(newRecord, newPTRZone.(string))
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		// The old record and PTR are swapped for the new ones as a unit
+		tx := newRecordTransaction(c)
+		if renamed {
+			// A rename writes the new name before removing the old one, so the record never stops resolving
+			if err := tx.create(newRecord); err != nil {
+				return diag.FromErr(fmt.Errorf("failed to create renamed record: %w", err))
+			}
+			if owner := a.ownerRecord(newRecord); owner != nil {
+				if err := tx.create(*owner); err != nil {
+					return diag.FromErr(tx.rollback(fmt.Errorf("failed to create ownership record %s: %w", owner.Name, err)))
+				}
+			}
+		}
+
+		valueChanged := d.HasChange("value") || current == nil
+		var oldRecord DNSRecord
+		if current != nil {
+			// Delete old record using actual stored value
+			oldRecord = newRecord
+			oldRecord.Name = oldName.(string)
+			oldRecord.Value = current.Value
+			if valueChanged && !renamed {
+				if err := tx.delete(oldRecord); err != nil {
+					return diag.FromErr(fmt.Errorf("failed to delete old record: %w", err))
+				}
+			}
+			// A PTR that no longer parses from state is left alone
+			if oldPTR, _ := ptrRecord(oldRecord, oldPTRZone.(string)); oldPTR != nil {
+				if err := tx.delete(*oldPTR); err != nil {
+					return diag.FromErr(tx.rollback(fmt.Errorf("failed to delete old PTR record: %w", err)))
+				}
+			}
+		}
+		if valueChanged && !renamed {
+			if err := tx.create(newRecord); err != nil {
+				return diag.FromErr(tx.rollback(fmt.Errorf("failed to create new record: %w", err)))
+			}
+		}
+		if newPTR != nil {
+			if err := tx.create(*newPTR); err != nil {
+				return diag.FromErr(tx.rollback(fmt.Errorf("failed to create PTR record %s: %w", joinFQDN(newPTR.Name, newPTR.Zone), err)))
+			}
+		}
+		if renamed && current != nil {
+			if owner := a.ownerRecord(oldRecord); owner != nil {
+				if err := tx.delete(*owner); err != nil {
+					return diag.FromErr(tx.rollback(fmt.Errorf("failed to delete ownership record %s: %w", owner.Name, err)))
+				}
+			}
+			if err := tx.delete(oldRecord); err != nil {
+				return diag.FromErr(tx.rollback(fmt.Errorf("failed to delete record under its old name %s: %w", oldRecord.Name, err)))
+			}
+			oldRecord.TTL = current.TTL
+			renamedFrom = &oldRecord
+		}
+		if renamed {
+			d.SetId(buildID(server, zone, newRecord.Name, recordType))
+		}
+
+		// A rewritten record is stored with default flags, which are written over like a new record's
+		if err := applyRecordFlags(c, d, newRecord, false); err != nil {
+			return diag.FromErr(err)
+		}
+
+		if err := verifyRecordResolution(ctx, d, newRecord); err != nil {
+			return diag.FromErr(err)
+		}
+		cachedTTL := 0
+		if current != nil {
+			cachedTTL = current.TTL
+		}
+		diags = append(diags, watchRecordCanaries(ctx, d, c, newRecord, cachedTTL)...)
+
+		diags = append(diags, setWriteMetadata(d, m)...)
+	} else if d.HasChanges("rank", "suppress_notify") {
+		record := DNSRecord{
+			Server: d.Get("dns_server").(string),
+			Zone:   d.Get("zone").(string),
+			Name:   d.Get("name").(string),
+			Type:   strings.ToUpper(d.Get("type").(string)),
+		}
+		if err := applyRecordFlags(c, d, record, true); err != nil {
+			return diag.FromErr(err)
 		}
 	}
 
-	return resourceRecordRead(ctx, d, m)
+	diags = append(diags, resourceRecordRead(ctx, d, m)...)
+	// samba-tool dns add cannot set a TTL, so a renamed record gets the server default
+	if renamedFrom != nil && renamedFrom.TTL != 0 && d.Get("ttl").(int) != renamedFrom.TTL {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%s was renamed with a TTL of %d instead of %d", joinFQDN(d.Get("name").(string), renamedFrom.Zone), d.Get("ttl").(int), renamedFrom.TTL),
+			Detail:   fmt.Sprintf("samba-tool cannot write TTLs, so the record at its new name has the server default. The old name %s had a TTL of %d.", joinFQDN(renamedFrom.Name, renamedFrom.Zone), renamedFrom.TTL),
+		})
+	}
+	return diags
 }
 
 func resourceRecordDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*apiClient).client
+	a := m.(*apiClient)
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
 
 	server := d.Get("dns_server").(string)
 	zone := d.Get("zone").(string)
 	name := d.Get("name").(string)
 	recordType := strings.ToUpper(d.Get("type").(string))
 
+	var diags diag.Diagnostics
+	if block := d.Get("block_if_referenced").(bool); block || d.Get("warn_if_referenced").(bool) {
+		aliases, err := referencingAliases(c, server, zone, name)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if len(aliases) > 0 {
+			fqdn := joinFQDN(name, zone)
+			if block {
+				return diag.Errorf("refusing to delete %s %s: CNAMEs still point at it (%s); repoint or remove them first, or unset block_if_referenced",
+					fqdn, recordType, strings.Join(aliases, ", "))
+			}
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Deleting %s leaves %d CNAME(s) dangling", fqdn, len(aliases)),
+				Detail:   fmt.Sprintf("These CNAMEs point at %s and stop resolving: %s", fqdn, strings.Join(aliases, ", ")),
+			})
+		}
+	}
+
 	// Query current record to get the actual stored value (may differ from config)
 	current, err := c.QueryRecord(server, zone, name, recordType)
 	if err != nil {
-		return diag.FromErr(fmt.Errorf("failed to query record for deletion: %w", err))
+		return append(diags, diag.FromErr(fmt.Errorf("failed to query record for deletion: %w", err))...)
 	}
 
 	// If record doesn't exist, nothing to delete
 	if current == nil {
 		d.SetId("")
-		return nil
+		return diags
 	}
 
 	// Delete using the actual stored value
@@ -251,10 +1040,38 @@ func resourceRecordDelete(ctx context.Context, d *schema.ResourceData, m interfa
 		Value:  current.Value,
 	}
 
-	if err := c.DeleteRecord(record); err != nil {
-		return diag.FromErr(fmt.Errorf("failed to delete record: %w", err))
+	tx := newRecordTransaction(c)
+	if err := tx.delete(record); err != nil {
+		return append(diags, diag.FromErr(fmt.Errorf("failed to delete record: %w", err))...)
+	}
+	if owner := a.ownerRecord(record); owner != nil {
+		if err := tx.delete(*owner); err != nil {
+			return append(diags, diag.FromErr(tx.rollback(fmt.Errorf("failed to delete ownership record %s: %w", owner.Name, err)))...)
+		}
+	}
+	if ptr, _ := ptrRecord(record, d.Get("ptr_zone").(string)); ptr != nil {
+		if err := tx.delete(*ptr); err != nil {
+			return append(diags, diag.FromErr(tx.rollback(fmt.Errorf("failed to delete PTR record %s: %w", joinFQDN(ptr.Name, ptr.Zone), err)))...)
+		}
 	}
 
 	d.SetId("")
-	return nil
+	return diags
+}
+
+// referencingAliases returns the fully qualified names of CNAMEs in zone that point at name
+func referencingAliases(c *SambaClient, server, zone, name string) ([]string, error) {
+	target := normalizeHostname(joinFQDN(name, zone))
+	records, _, err := c.ListZoneRecordsFiltered(server, zone, zoneFilter{Types: map[string]bool{"CNAME": true}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan zone for CNAMEs pointing at %s: %w", joinFQDN(name, zone), err)
+	}
+
+	var aliases []string
+	for _, r := range records {
+		if normalizeHostname(r.Value) == target {
+			aliases = append(aliases, joinFQDN(r.Name, zone))
+		}
+	}
+	return aliases, nil
 }
@@ -0,0 +1,372 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// recordSetTypes are the types a name can hold several records of
+var recordSetTypes = []string{"A", "AAAA", "TXT", "MX", "PTR", "SRV", "NS"}
+
+// orderedRecordSetType reports whether values of a type are compared as a list
+// MX and SRV values carry their own priority and weight, so their configured order is kept;
+// for every other type the order is meaningless and values are compared as a set
+func orderedRecordSetType(recordType string) bool {
+	switch strings.ToUpper(recordType) {
+	case "MX", "SRV":
+		return true
+	}
+	return false
+}
+
+// resourceRecordSetExample is the example configuration shown in the resource documentation
+const resourceRecordSetExample = `
+resource "sambadns_record_set" "apex_txt" {
+  server = "dc01.example.com"
+  zone   = "example.com"
+  name   = "@"
+  type   = "TXT"
+  values = [
+    "v=spf1 mx -all",
+    "google-site-verification=abc123",
+  ]
+}
+`
+
+func resourceRecordSet() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Manages every record of one type at a name (e.g., several TXT records at the apex). "+
+			"Values are compared after normalization; for MX and SRV they are compared as a list, for other types as a set.",
+			resourceRecordSetExample, "terraform import sambadns_record_set.apex_txt \"dc01.example.com/example.com/@/TXT\""),
+
+		CreateContext: wrapCRUD(resourceRecordSetCreate),
+		ReadContext:   wrapCRUD(resourceRecordSetRead),
+		UpdateContext: wrapCRUD(resourceRecordSetUpdate),
+		DeleteContext: wrapCRUD(resourceRecordSetDelete),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		CustomizeDiff: customdiff.All(
+			validateNamePolicy,
+			validateRecordSetValues,
+			summarizeChange("sambadns_record_set", "values"),
+			estimateOperations(recordSetOperations),
+		),
+
+		Schema: withPlanEstimate(map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS zone name (e.g., example.com).",
+			},
+			"name": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateFunc:     validateDNSName,
+				DiffSuppressFunc: suppressEquivalentName,
+				Description:      "Record name shared by all values (`@` for the apex).",
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(recordSetTypes, true),
+				StateFunc:    func(v interface{}) string { return strings.ToUpper(v.(string)) },
+				Description:  "Record type (A, AAAA, TXT, MX, PTR, SRV, NS).",
+			},
+			"values": {
+				Type:             schema.TypeList,
+				Required:         true,
+				MinItems:         1,
+				Elem:             &schema.Schema{Type: schema.TypeString, ValidateFunc: validateNoTemplatePlaceholders},
+				DiffSuppressFunc: suppressRecordSetDiff,
+				Description:      "Record values, in the same format as `sambadns_record`. Reordering MX and SRV values is a change; reordering other types is not.",
+			},
+			"max_zone_records": maxZoneRecordsSchema(),
+			"profile":          profileSchema(),
+			"credentials":      credentialsSchema(),
+			"retry":            resourceRetrySchema(),
+			"write_metadata":   writeMetadataSchema(),
+		}),
+	}
+}
+
+// validateRecordSetValues applies the value checks of sambadns_record to every value of the set:
+// address literals and the provider allowed_cidrs for A and AAAA, NUL bytes and string length for TXT
+func validateRecordSetValues(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("type") || !d.NewValueKnown("values") {
+		return nil
+	}
+	for _, value := range setToStrings(d.Get("values").([]interface{})) {
+		if err := checkRecordValue(m, d.Get("type").(string), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// suppressRecordSetDiff hides differences between equivalent value lists
+// A list's diff is reported per element, so the whole old and new lists are compared
+func suppressRecordSetDiff(k, old, new string, d *schema.ResourceData) bool {
+	oldRaw, newRaw := d.GetChange("values")
+	return recordSetsEqual(d.Get("type").(string), setToStrings(oldRaw.([]interface{})), setToStrings(newRaw.([]interface{})))
+}
+
+// recordSetsEqual compares value lists under the rules of orderedRecordSetType
+func recordSetsEqual(recordType string, a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	if orderedRecordSetType(recordType) {
+		for i := range a {
+			if !recordValuesEqual(recordType, a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	added, removed := recordSetDiff(recordType, a, b)
+	return len(added) == 0 && len(removed) == 0
+}
+
+// recordSetDiff returns the values only in newValues and only in oldValues, compared canonically
+// Duplicates count, so [x, x] and [x] differ by one x
+func recordSetDiff(recordType string, oldValues, newValues []string) (added, removed []string) {
+	pending := make(map[string]int, len(oldValues))
+	for _, v := range oldValues {
+		pending[canonicalValue(recordType, v)]++
+	}
+	for _, v := range newValues {
+		key := canonicalValue(recordType, v)
+		if pending[key] > 0 {
+			pending[key]--
+			continue
+		}
+		added = append(added, v)
+	}
+	for i := len(oldValues) - 1; i >= 0; i-- {
+		key := canonicalValue(recordType, oldValues[i])
+		if pending[key] > 0 {
+			pending[key]--
+			removed = append([]string{oldValues[i]}, removed...)
+		}
+	}
+	return added, removed
+}
+
+// recordSetOperations counts the records a plan adds and removes
+func recordSetOperations(d *schema.ResourceDiff) (int, bool) {
+	if !d.NewValueKnown("values") {
+		return 0, false
+	}
+	oldRaw, newRaw := d.GetChange("values")
+	added, removed := recordSetDiff(d.Get("type").(string), setToStrings(oldRaw.([]interface{})), setToStrings(newRaw.([]interface{})))
+	return len(added) + len(removed), true
+}
+
+// recordSetRecords builds the records for values of one type at one name
+func recordSetRecords(server, zone, name, recordType string, values []string) []DNSRecord {
+	records := make([]DNSRecord, 0, len(values))
+	for _, value := range values {
+		records = append(records, DNSRecord{
+			Server: server,
+			Zone:   zone,
+			Name:   name,
+			Type:   recordType,
+			Value:  value,
+		})
+	}
+	return records
+}
+
+func resourceRecordSetCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+	name := d.Get("name").(string)
+	recordType := strings.ToUpper(d.Get("type").(string))
+	values := setToStrings(d.Get("values").([]interface{}))
+
+	if err := checkZoneQuota(d, c, server, zone, len(values)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := applyRecordChanges(c, recordSetRecords(server, zone, name, recordType, values), nil); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(buildID(server, zone, name, recordType))
+
+	diags := setWriteMetadata(d, m)
+
+	return append(diags, resourceRecordSetRead(ctx, d, m)...)
+}
+
+func resourceRecordSetRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server, zone, name, recordType, err := parseID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(buildID(server, zone, name, recordType))
+
+	records, err := c.QueryName(server, zone, name)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to query record set: %w", err))
+	}
+
+	var live []string
+	for _, r := range records {
+		if r.Type == recordType {
+			live = append(live, r.Value)
+		}
+	}
+	if len(live) == 0 {
+		d.SetId("")
+		return nil
+	}
+
+	state := newStateSetter(d)
+	state.set("dns_server", server)
+	state.set("zone", zone)
+	state.set("name", name)
+	state.set("type", recordType)
+	state.set("values", orderLikeConfig(recordType, setToStrings(d.Get("values").([]interface{})), live))
+
+	return state.diags
+}
+
+// orderLikeConfig arranges live values in the configured order and spelling
+// Values the server holds that are not configured follow in server order
+func orderLikeConfig(recordType string, configured, live []string) []string {
+	remaining := make(map[string][]string, len(live))
+	var order []string
+	for _, v := range live {
+		key := canonicalValue(recordType, v)
+		if _, seen := remaining[key]; !seen {
+			order = append(order, key)
+		}
+		remaining[key] = append(remaining[key], v)
+	}
+
+	values := make([]string, 0, len(live))
+	for _, v := range configured {
+		key := canonicalValue(recordType, v)
+		if len(remaining[key]) > 0 {
+			remaining[key] = remaining[key][1:]
+			values = append(values, v)
+		}
+	}
+	for _, key := range order {
+		values = append(values, remaining[key]...)
+	}
+	return values
+}
+
+// storedValues returns the server's spelling of values, for samba-tool delete
+// Values the server no longer holds are dropped
+func storedValues(c *SambaClient, server, zone, name, recordType string, values []string) ([]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	records, err := c.QueryName(server, zone, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query record set: %w", err)
+	}
+
+	wanted := make(map[string]int, len(values))
+	for _, v := range values {
+		wanted[canonicalValue(recordType, v)]++
+	}
+	var stored []string
+	for _, r := range records {
+		key := canonicalValue(recordType, r.Value)
+		if r.Type == recordType && wanted[key] > 0 {
+			wanted[key]--
+			stored = append(stored, r.Value)
+		}
+	}
+	return stored, nil
+}
+
+func resourceRecordSetUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.HasChange("values") {
+		server := d.Get("dns_server").(string)
+		zone := d.Get("zone").(string)
+		name := d.Get("name").(string)
+		recordType := strings.ToUpper(d.Get("type").(string))
+
+		// A reordered MX or SRV list changes no record, only the stored order
+		oldRaw, newRaw := d.GetChange("values")
+		added, removed := recordSetDiff(recordType, setToStrings(oldRaw.([]interface{})), setToStrings(newRaw.([]interface{})))
+
+		removed, err := storedValues(c, server, zone, name, recordType, removed)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+
+		adds := recordSetRecords(server, zone, name, recordType, added)
+		removes := recordSetRecords(server, zone, name, recordType, removed)
+		if err := checkZoneQuota(d, c, server, zone, len(adds)-len(removes)); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := applyRecordChanges(c, adds, removes); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	diags := setWriteMetadata(d, m)
+
+	return append(diags, resourceRecordSetRead(ctx, d, m)...)
+}
+
+func resourceRecordSetDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+	name := d.Get("name").(string)
+	recordType := strings.ToUpper(d.Get("type").(string))
+	values, err := storedValues(c, server, zone, name, recordType, setToStrings(d.Get("values").([]interface{})))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := applyRecordChanges(c, nil, recordSetRecords(server, zone, name, recordType, values)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return nil
+}
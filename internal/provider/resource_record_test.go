@@ -0,0 +1,31 @@
+package provider
+
+import "testing"
+
+// TestRecordTTLSource checks that ttl_source follows what the server stores, not the configuration
+func TestRecordTTLSource(t *testing.T) {
+	c := newFileTestClient(t)
+	// The file backend's SOA has minttl=3600
+	tests := []struct {
+		name   string
+		record DNSRecord
+		want   string
+	}{
+		{"zone minimum", DNSRecord{TTL: 3600}, ttlSourceZoneDefault},
+		{"samba-tool default", DNSRecord{TTL: 900}, ttlSourceExplicit},
+		{"default-TTL flag", DNSRecord{TTL: 900, Flags: dnsRPCFlagRecordDefaultTTL}, ttlSourceZoneDefault},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.record.Server, tt.record.Zone, tt.record.Name, tt.record.Type = "dc", "example.com", "web", "A"
+			got, ok := recordTTLSource(c, &tt.record)
+			if !ok || got != tt.want {
+				t.Errorf("recordTTLSource = %q, %v, want %q", got, ok, tt.want)
+			}
+		})
+	}
+
+	if _, ok := recordTTLSource(c, &DNSRecord{Server: "dc", Zone: "missing.example", TTL: 900}); ok {
+		t.Error("recordTTLSource reported a source for a zone without an SOA")
+	}
+}
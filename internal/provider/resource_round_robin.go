@@ -0,0 +1,252 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceRoundRobinExample is the example configuration shown in the resource documentation
+const resourceRoundRobinExample = `
+resource "sambadns_round_robin" "web" {
+  server    = "dc01.example.com"
+  zone      = "example.com"
+  name      = "web"
+  addresses = ["10.0.0.11", "10.0.0.12", "10.0.0.13", "fd00::11"]
+}
+`
+
+func resourceRoundRobin() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Manages a round-robin set of A/AAAA records for one name. Addresses are compared as a set, "+
+			"so reordering produces no diff and adding or removing an address touches only that record.",
+			resourceRoundRobinExample, "terraform import sambadns_round_robin.web \"dc01.example.com/example.com/web\""),
+
+		CreateContext: wrapCRUD(resourceRoundRobinCreate),
+		ReadContext:   wrapCRUD(resourceRoundRobinRead),
+		UpdateContext: wrapCRUD(resourceRoundRobinUpdate),
+		DeleteContext: wrapCRUD(resourceRoundRobinDelete),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		CustomizeDiff: customdiff.All(
+			validateRoundRobinPolicy,
+			estimateOperations(func(d *schema.ResourceDiff) (int, bool) { return setChangeCount(d, "addresses") }),
+		),
+
+		Schema: withPlanEstimate(map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS zone name (e.g., example.com).",
+			},
+			"name": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateFunc:     validateDNSName,
+				DiffSuppressFunc: suppressEquivalentName,
+				Description:      "Record name shared by all addresses.",
+			},
+			"addresses": {
+				Type:        schema.TypeSet,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validateAddressLiteral},
+				Description: "IP addresses to publish. IPv4 addresses become A records, IPv6 addresses AAAA records.",
+			},
+			"max_zone_records": maxZoneRecordsSchema(),
+			"profile":          profileSchema(),
+			"credentials":      credentialsSchema(),
+			"retry":            resourceRetrySchema(),
+			"write_metadata":   writeMetadataSchema(),
+		}),
+	}
+}
+
+// addressRecordType returns A or AAAA for an IP address
+// IPv4-mapped addresses such as ::ffff:10.0.0.1 are written in IPv6 notation and go in AAAA records
+func addressRecordType(address string) string {
+	if ip := net.ParseIP(address); ip != nil && strings.Contains(address, ":") {
+		return "AAAA"
+	}
+	return "A"
+}
+
+// roundRobinRecords builds the records for a set of addresses at one name
+func roundRobinRecords(server, zone, name string, addresses []string) []DNSRecord {
+	records := make([]DNSRecord, 0, len(addresses))
+	for _, address := range addresses {
+		records = append(records, DNSRecord{
+			Server: server,
+			Zone:   zone,
+			Name:   name,
+			Type:   addressRecordType(address),
+			Value:  address,
+		})
+	}
+	return records
+}
+
+// validateRoundRobinPolicy applies the provider naming and address policies at plan time
+func validateRoundRobinPolicy(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if m == nil {
+		return nil
+	}
+	a := m.(*apiClient)
+	if d.NewValueKnown("name") {
+		if err := a.checkNamePolicy(d.Get("name").(string)); err != nil {
+			return err
+		}
+	}
+	if d.NewValueKnown("addresses") {
+		for _, address := range setToStrings(d.Get("addresses").(*schema.Set).List()) {
+			if err := checkAllowedAddress(a.allowedCIDRs, addressRecordType(address), address); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func resourceRoundRobinCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+	name := d.Get("name").(string)
+	addresses := setToStrings(d.Get("addresses").(*schema.Set).List())
+
+	if err := checkZoneQuota(d, c, server, zone, len(addresses)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := applyRecordChanges(c, roundRobinRecords(server, zone, name, addresses), nil); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(buildNameID(server, zone, name))
+
+	diags := setWriteMetadata(d, m)
+
+	return append(diags, resourceRoundRobinRead(ctx, d, m)...)
+}
+
+func resourceRoundRobinRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server, zone, name, err := parseNameID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	records, err := c.QueryName(server, zone, name)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to query round robin: %w", err))
+	}
+
+	// Keep the configured spelling of addresses the server reports in another form
+	configured := make(map[string]string)
+	if v, ok := d.GetOk("addresses"); ok {
+		for _, address := range setToStrings(v.(*schema.Set).List()) {
+			configured[normalizeIPv6(address)] = address
+		}
+	}
+
+	var addresses []string
+	for _, r := range records {
+		if r.Type != "A" && r.Type != "AAAA" {
+			continue
+		}
+		if address, ok := configured[normalizeIPv6(r.Value)]; ok {
+			addresses = append(addresses, address)
+		} else {
+			addresses = append(addresses, r.Value)
+		}
+	}
+
+	if len(addresses) == 0 {
+		d.SetId("")
+		return nil
+	}
+	sort.Strings(addresses)
+
+	state := newStateSetter(d)
+	state.set("dns_server", server)
+	state.set("zone", zone)
+	state.set("name", name)
+	state.set("addresses", addresses)
+
+	return state.diags
+}
+
+func resourceRoundRobinUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.HasChange("addresses") {
+		server := d.Get("dns_server").(string)
+		zone := d.Get("zone").(string)
+		name := d.Get("name").(string)
+
+		oldRaw, newRaw := d.GetChange("addresses")
+		added, removed := stringSetDiff(
+			setToStrings(oldRaw.(*schema.Set).List()),
+			setToStrings(newRaw.(*schema.Set).List()),
+		)
+
+		adds := roundRobinRecords(server, zone, name, added)
+		removes := roundRobinRecords(server, zone, name, removed)
+		if err := checkZoneQuota(d, c, server, zone, len(adds)-len(removes)); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := applyRecordChanges(c, adds, removes); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	diags := setWriteMetadata(d, m)
+
+	return append(diags, resourceRoundRobinRead(ctx, d, m)...)
+}
+
+func resourceRoundRobinDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+	name := d.Get("name").(string)
+	addresses := setToStrings(d.Get("addresses").(*schema.Set).List())
+
+	if err := applyRecordChanges(c, nil, roundRobinRecords(server, zone, name, addresses)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,288 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// resourceSplitRecordExample is the example configuration shown in the resource documentation
+const resourceSplitRecordExample = `
+resource "sambadns_split_record" "portal" {
+  zone = "example.com"
+  name = "portal"
+  type = "A"
+
+  per_server = {
+    "dc-internal.example.com" = "10.0.0.50"
+    "dc-dmz.example.com"      = "203.0.113.50"
+  }
+}
+`
+
+func resourceSplitRecord() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Manages one logical record across several independent DNS servers, with a value per server "+
+			"(e.g., different answers on the internal and DMZ DCs).",
+			resourceSplitRecordExample, "terraform import sambadns_split_record.portal \"dc-dmz.example.com,dc-internal.example.com/example.com/portal/A\""),
+
+		CreateContext: wrapCRUD(resourceSplitRecordCreate),
+		ReadContext:   wrapCRUD(resourceSplitRecordRead),
+		UpdateContext: wrapCRUD(resourceSplitRecordUpdate),
+		DeleteContext: wrapCRUD(resourceSplitRecordDelete),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		CustomizeDiff: customdiff.All(
+			validateNamePolicy,
+			validateSplitRecordValues,
+		),
+
+		Schema: map[string]*schema.Schema{
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS zone name (e.g., example.com). Must exist on every server.",
+			},
+			"name": {
+				Type:             schema.TypeString,
+				Required:         true,
+				ForceNew:         true,
+				ValidateFunc:     validateDNSName,
+				DiffSuppressFunc: suppressEquivalentName,
+				Description:      "Record name.",
+			},
+			"type": {
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(supportedRecordTypes, true),
+				StateFunc:    func(v interface{}) string { return strings.ToUpper(v.(string)) },
+				Description:  "Record type (A, AAAA, CNAME, TXT, MX, PTR, SRV, NS).",
+			},
+			"per_server": {
+				Type:             schema.TypeMap,
+				Required:         true,
+				Elem:             &schema.Schema{Type: schema.TypeString, ValidateFunc: validateNoTemplatePlaceholders},
+				DiffSuppressFunc: suppressValueDiff,
+				Description:      "Map of DNS server hostname to the value published on that server.",
+			},
+			"profile":        profileSchema(),
+			"credentials":    credentialsSchema(),
+			"retry":          resourceRetrySchema(),
+			"write_metadata": writeMetadataSchema(),
+		},
+	}
+}
+
+// buildSplitRecordID is a record ID whose server part lists every server, sorted and comma separated
+func buildSplitRecordID(zone, name, recordType string, perServer map[string]interface{}) string {
+	servers := make([]string, 0, len(perServer))
+	for server := range perServer {
+		servers = append(servers, server)
+	}
+	sort.Strings(servers)
+	return buildID(strings.Join(servers, ","), zone, name, recordType)
+}
+
+// parseSplitRecordID is the inverse of buildSplitRecordID
+func parseSplitRecordID(id string) (servers []string, zone, name, recordType string, err error) {
+	serverList, zone, name, recordType, err := parseID(id)
+	if err != nil {
+		return nil, "", "", "", err
+	}
+	for _, server := range strings.Split(serverList, ",") {
+		if server = strings.TrimSpace(server); server != "" {
+			servers = append(servers, server)
+		}
+	}
+	if len(servers) == 0 {
+		return nil, "", "", "", fmt.Errorf("invalid ID format: %s (expected server1,server2/zone/name/type)", id)
+	}
+	return servers, zone, name, recordType, nil
+}
+
+// validateSplitRecordValues applies the value checks of sambadns_record to every per-server value
+func validateSplitRecordValues(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("type") || !d.NewValueKnown("per_server") {
+		return nil
+	}
+	for server, value := range d.Get("per_server").(map[string]interface{}) {
+		if err := checkRecordValue(m, d.Get("type").(string), value.(string)); err != nil {
+			return fmt.Errorf("per_server[%q]: %w", server, err)
+		}
+	}
+	return nil
+}
+
+// splitRecord builds the record published on one server
+func splitRecord(d *schema.ResourceData, server, value string) DNSRecord {
+	return DNSRecord{
+		Server: server,
+		Zone:   d.Get("zone").(string),
+		Name:   d.Get("name").(string),
+		Type:   strings.ToUpper(d.Get("type").(string)),
+		Value:  value,
+	}
+}
+
+// replaceRecordValue swaps the value stored on the server for a new one, deleting by the stored value
+func replaceRecordValue(c *SambaClient, r DNSRecord) error {
+	current, err := c.QueryRecord(r.Server, r.Zone, r.Name, r.Type)
+	if err != nil {
+		return fmt.Errorf("failed to query record on %s: %w", r.Server, err)
+	}
+	if current != nil {
+		old := r
+		old.Value = current.Value
+		if err := c.DeleteRecord(old); err != nil {
+			return fmt.Errorf("failed to delete old record on %s: %w", r.Server, err)
+		}
+	}
+	if err := c.CreateRecord(r); err != nil {
+		return fmt.Errorf("failed to create record on %s: %w", r.Server, err)
+	}
+	return nil
+}
+
+func resourceSplitRecordCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	perServer := d.Get("per_server").(map[string]interface{})
+	d.SetId(buildSplitRecordID(d.Get("zone").(string), d.Get("name").(string), d.Get("type").(string), perServer))
+
+	for server, value := range perServer {
+		if err := c.CreateRecord(splitRecord(d, server, value.(string))); err != nil {
+			return diag.FromErr(fmt.Errorf("failed to create record on %s: %w", server, err))
+		}
+	}
+
+	diags := setWriteMetadata(d, m)
+
+	return append(diags, resourceSplitRecordRead(ctx, d, m)...)
+}
+
+func resourceSplitRecordRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The ID names the servers, so an imported record is read from them
+	servers, zone, name, recordType, err := parseSplitRecordID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	live := make(map[string]interface{})
+	for _, server := range servers {
+		record, err := c.QueryRecord(server, zone, name, recordType)
+		if err != nil {
+			return diag.FromErr(fmt.Errorf("failed to query record on %s: %w", server, err))
+		}
+		if record != nil {
+			live[server] = record.Value
+		}
+	}
+
+	if len(live) == 0 {
+		d.SetId("")
+		return nil
+	}
+
+	state := newStateSetter(d)
+	state.set("zone", zone)
+	state.set("name", name)
+	state.set("type", recordType)
+	state.set("per_server", live)
+
+	return state.diags
+}
+
+func resourceSplitRecordUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if d.HasChange("per_server") {
+		oldRaw, newRaw := d.GetChange("per_server")
+		oldMap := oldRaw.(map[string]interface{})
+		newMap := newRaw.(map[string]interface{})
+
+		for server, value := range newMap {
+			record := splitRecord(d, server, value.(string))
+			oldValue, existed := oldMap[server]
+			switch {
+			case !existed:
+				if err := c.CreateRecord(record); err != nil {
+					return diag.FromErr(fmt.Errorf("failed to create record on %s: %w", server, err))
+				}
+			case !recordValuesEqual(record.Type, oldValue.(string), record.Value):
+				if err := replaceRecordValue(c, record); err != nil {
+					return diag.FromErr(err)
+				}
+			}
+		}
+
+		for server := range oldMap {
+			if _, kept := newMap[server]; kept {
+				continue
+			}
+			if err := deleteStoredRecord(c, splitRecord(d, server, "")); err != nil {
+				return diag.FromErr(err)
+			}
+		}
+
+		// The ID lists the servers the record is published on
+		d.SetId(buildSplitRecordID(d.Get("zone").(string), d.Get("name").(string), d.Get("type").(string), newMap))
+	}
+
+	diags := setWriteMetadata(d, m)
+
+	return append(diags, resourceSplitRecordRead(ctx, d, m)...)
+}
+
+func resourceSplitRecordDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	for server := range d.Get("per_server").(map[string]interface{}) {
+		if err := deleteStoredRecord(c, splitRecord(d, server, "")); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// deleteStoredRecord deletes a record by the value actually stored on its server
+func deleteStoredRecord(c *SambaClient, r DNSRecord) error {
+	current, err := c.QueryRecord(r.Server, r.Zone, r.Name, r.Type)
+	if err != nil {
+		return fmt.Errorf("failed to query record on %s: %w", r.Server, err)
+	}
+	if current == nil {
+		return nil
+	}
+	r.Value = current.Value
+	if err := c.DeleteRecord(r); err != nil {
+		return fmt.Errorf("failed to delete record on %s: %w", r.Server, err)
+	}
+	return nil
+}
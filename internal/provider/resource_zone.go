@@ -0,0 +1,330 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// resourceZoneExample is the example configuration shown in the resource documentation
+const resourceZoneExample = `
+resource "sambadns_zone" "lab" {
+  server              = "dc01.example.com"
+  zone                = "lab.example.com"
+  directory_partition = "forest"
+}
+`
+
+func resourceZone() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Manages an AD-integrated DNS zone. Destroying the resource deletes the zone; a zone that still holds records is only deleted with `force_destroy`.",
+			resourceZoneExample, "terraform import sambadns_zone.lab \"dc01.example.com/lab.example.com\""),
+
+		CreateContext: wrapCRUD(resourceZoneCreate),
+		ReadContext:   wrapCRUD(resourceZoneRead),
+		UpdateContext: wrapCRUD(resourceZoneUpdate),
+		DeleteContext: wrapCRUD(resourceZoneDelete),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceZoneImport,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "Zone name (e.g., lab.example.com or 10.in-addr.arpa).",
+			},
+			"directory_partition": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ForceNew:      true,
+				ValidateFunc:  validation.StringInSlice([]string{"domain", "forest"}, false),
+				ConflictsWith: []string{"directory_partition_dn"},
+				Description:   "Application partition the zone is stored in: `domain` (DomainDnsZones, the default) or `forest` (ForestDnsZones).",
+			},
+			"directory_partition_dn": {
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ValidateFunc:  validatePartitionDN,
+				ConflictsWith: []string{"directory_partition"},
+				Description:   "Distinguished name of the partition to store the zone in (e.g., `DC=ForestDnsZones,DC=example,DC=com`).",
+			},
+			"force_destroy": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Delete the zone on destroy even when it still holds records. Without it, destroying a zone with records other than its apex SOA and NS fails.",
+			},
+			// Computed attributes
+			"zone_type": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Zone type reported by zoneinfo: `primary`, `secondary`, `stub` or `forwarder`.",
+			},
+			"reverse": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the zone is a reverse lookup zone.",
+			},
+			"replication_scope": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Where the zone replicates: `domain`, `forest`, `legacy` (the domain partition, pre-2003 style) or `custom` (another application partition).",
+			},
+			"zone_dn": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Distinguished name of the zone object.",
+			},
+			"dynamic_update": {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Dynamic update policy: `none`, `nonsecure` (secure and nonsecure) or `secure`.",
+			},
+			"aging": {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether aging and scavenging is enabled for the zone.",
+			},
+			"no_refresh_interval": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Aging no-refresh interval, in hours.",
+			},
+			"refresh_interval": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Aging refresh interval, in hours.",
+			},
+			"profile":     profileSchema(),
+			"credentials": credentialsSchema(),
+			"retry":       resourceRetrySchema(),
+		},
+	}
+}
+
+// resourceZoneImport accepts server/zone, ignoring a trailing dot on the zone
+// Import adopts the zone as it is; every setting is read back from zoneinfo by Read
+func resourceZoneImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	server, zone, found := strings.Cut(d.Id(), "/")
+	if !found || server == "" || zone == "" {
+		return nil, fmt.Errorf("invalid import ID %q (expected server/zone, e.g. dc01.example.com/example.com)", d.Id())
+	}
+	d.SetId(fmt.Sprintf("%s/%s", server, strings.TrimSuffix(zone, ".")))
+	return []*schema.ResourceData{d}, nil
+}
+
+// zoneSettings converts zoneinfo fields into the zone's computed attributes
+// Example fields:
+//
+//	dwZoneType                  : DNS_ZONE_TYPE_PRIMARY
+//	fAllowUpdate                : DNS_ZONE_UPDATE_SECURE
+//	fAging                      : FALSE
+//	dwNoRefreshInterval         : 168
+//	dwDpFlags                   : DNS_DP_AUTOCREATED DNS_DP_DOMAIN_DEFAULT DNS_DP_ENLISTED
+func zoneSettings(info map[string]string) map[string]interface{} {
+	zoneType := strings.ToLower(strings.TrimPrefix(info["dwZoneType"], "DNS_ZONE_TYPE_"))
+
+	scope := "custom"
+	switch flags := info["dwDpFlags"]; {
+	case strings.Contains(flags, "DNS_DP_DOMAIN_DEFAULT"):
+		scope = "domain"
+	case strings.Contains(flags, "DNS_DP_FOREST_DEFAULT"):
+		scope = "forest"
+	case strings.Contains(flags, "DNS_DP_LEGACY"):
+		scope = "legacy"
+	}
+
+	// Older samba-tool versions print the numeric value
+	update := "none"
+	switch v := info["fAllowUpdate"]; {
+	case strings.Contains(v, "UNSECURE"), v == "1":
+		update = "nonsecure"
+	case strings.Contains(v, "SECURE"), v == "2":
+		update = "secure"
+	}
+
+	noRefresh, _ := strconv.Atoi(info["dwNoRefreshInterval"])
+	refresh, _ := strconv.Atoi(info["dwRefreshInterval"])
+
+	return map[string]interface{}{
+		"zone_type":           zoneType,
+		"reverse":             strings.EqualFold(info["fReverse"], "TRUE"),
+		"replication_scope":   scope,
+		"zone_dn":             info["pwszZoneDn"],
+		"dynamic_update":      update,
+		"aging":               strings.EqualFold(info["fAging"], "TRUE"),
+		"no_refresh_interval": noRefresh,
+		"refresh_interval":    refresh,
+	}
+}
+
+// partitionFromDN maps a partition DN to the samba-tool partition name
+// samba-tool can only place zones in the built-in DomainDnsZones and ForestDnsZones partitions
+func partitionFromDN(dn string) (string, error) {
+	first, _, _ := strings.Cut(dn, ",")
+	switch strings.ToLower(strings.ReplaceAll(first, " ", "")) {
+	case "dc=domaindnszones":
+		return "domain", nil
+	case "dc=forestdnszones":
+		return "forest", nil
+	}
+	return "", fmt.Errorf("partition %q is not supported: samba-tool can only create zones in DomainDnsZones or ForestDnsZones", dn)
+}
+
+func validatePartitionDN(v interface{}, k string) (warnings []string, errs []error) {
+	if _, err := partitionFromDN(v.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q: %w", k, err))
+	}
+	return warnings, errs
+}
+
+// partitionFromZoneInfo derives the partition name from zoneinfo's pszDpFqdn (e.g., ForestDnsZones.example.com)
+func partitionFromZoneInfo(info map[string]string) string {
+	label, _, _ := strings.Cut(info["pszDpFqdn"], ".")
+	switch strings.ToLower(label) {
+	case "domaindnszones":
+		return "domain"
+	case "forestdnszones":
+		return "forest"
+	}
+	return ""
+}
+
+func resourceZoneCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := zoneClientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+
+	partition := "domain"
+	if v := d.Get("directory_partition").(string); v != "" {
+		partition = v
+	}
+	if v := d.Get("directory_partition_dn").(string); v != "" {
+		if partition, err = partitionFromDN(v); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err := c.CreateZone(server, zone, partition); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to create zone: %w", err))
+	}
+
+	d.SetId(fmt.Sprintf("%s/%s", server, zone))
+
+	return resourceZoneRead(ctx, d, m)
+}
+
+func resourceZoneRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server, zone, found := strings.Cut(d.Id(), "/")
+	if !found {
+		return diag.Errorf("invalid ID format: %s (expected server/zone)", d.Id())
+	}
+
+	info, err := c.ZoneInfo(server, zone)
+	if err != nil {
+		if isZoneNotExistError(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(fmt.Errorf("failed to query zone: %w", err))
+	}
+
+	state := newStateSetter(d)
+	state.set("dns_server", server)
+	state.set("zone", zone)
+	if partition := partitionFromZoneInfo(info); partition != "" {
+		state.set("directory_partition", partition)
+	}
+	for k, v := range zoneSettings(info) {
+		state.set(k, v)
+	}
+
+	return state.diags
+}
+
+func resourceZoneUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Only credentials, profile, retry and force_destroy can change in place; the zone itself is untouched
+	return resourceZoneRead(ctx, d, m)
+}
+
+func resourceZoneDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := zoneClientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+
+	if !d.Get("force_destroy").(bool) {
+		if err := checkZoneEmpty(c, server, zone); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err := c.DeleteZone(server, zone); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to delete zone: %w", err))
+	}
+
+	d.SetId("")
+	return nil
+}
+
+// zoneEmptySample is how many remaining records a refused zone delete lists
+const zoneEmptySample = 5
+
+// checkZoneEmpty fails when a zone holds records other than the SOA and NS records samba-tool creates at its apex
+// The walk stops at the first few records found, so a large zone costs no more than a small one
+func checkZoneEmpty(c *SambaClient, server, zone string) error {
+	records, more, err := c.ListZoneRecordsFiltered(server, zone, zoneFilter{
+		Limit: zoneEmptySample,
+		Exclude: func(r DNSRecord) bool {
+			return r.Name == "@" && (r.Type == "SOA" || r.Type == "NS")
+		},
+	})
+	if err != nil {
+		if isZoneNotExistError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to check that zone %s is empty: %w", zone, err)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+
+	sample := make([]string, len(records))
+	for i, r := range records {
+		sample[i] = fmt.Sprintf("%s %s %s", r.Name, r.Type, r.Value)
+	}
+	count := fmt.Sprintf("%d", len(records))
+	if more {
+		count = "more than " + count
+	}
+	return fmt.Errorf("zone %s still holds %s records (%s); delete them first, or set force_destroy = true and apply before destroying",
+		zone, count, strings.Join(sample, "; "))
+}
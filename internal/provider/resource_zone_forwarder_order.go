@@ -0,0 +1,214 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// resourceZoneForwarderOrderExample is the example configuration shown in the resource documentation
+const resourceZoneForwarderOrderExample = `
+resource "sambadns_zone_forwarder_order" "dc01" {
+  server          = "dc01.example.com"
+  forwarders      = ["10.0.0.53", "10.0.1.53"]
+  timeout         = 5
+  forwarders_only = true
+}
+`
+
+func resourceZoneForwarderOrder() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Manages the ordered forwarders, forwarder timeout and forwarders-only recursion of a DNS server, "+
+			"or of one of its conditional forwarder zones. Backends that cannot write forwarder settings (samba-tool) only check them: "+
+			"the apply fails with guidance unless the server already forwards as configured. Destroying it leaves the settings in place.",
+			resourceZoneForwarderOrderExample, "terraform import sambadns_zone_forwarder_order.dc01 \"dc01.example.com\""),
+
+		CreateContext: wrapCRUD(resourceZoneForwarderOrderCreate),
+		ReadContext:   wrapCRUD(resourceZoneForwarderOrderRead),
+		UpdateContext: wrapCRUD(resourceZoneForwarderOrderUpdate),
+		DeleteContext: wrapCRUD(resourceZoneForwarderOrderDelete),
+
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+
+		Schema: map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Optional:    true,
+				ForceNew:    true,
+				Description: "Conditional forwarder zone to manage. Left out, the server-wide forwarders are managed.",
+			},
+			"forwarders": {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString, ValidateFunc: validation.IsIPAddress},
+				Description: "Forwarder addresses, in the order they are tried.",
+			},
+			"timeout": {
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntBetween(1, 60),
+				Description:  "Seconds each forwarder gets to answer before the next one is tried. Left out, the server's setting is kept.",
+			},
+			"forwarders_only": {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Computed:    true,
+				Description: "Fail queries no forwarder answers instead of recursing from the root hints. Left out, the server's setting is kept.",
+			},
+			"profile":        profileSchema(),
+			"credentials":    credentialsSchema(),
+			"retry":          resourceRetrySchema(),
+			"write_metadata": writeMetadataSchema(),
+		},
+	}
+}
+
+func resourceZoneForwarderOrderCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := applyForwarderSettings(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	d.SetId(buildForwarderID(d.Get("dns_server").(string), d.Get("zone").(string)))
+
+	diags = append(diags, setWriteMetadata(d, m)...)
+
+	return append(diags, resourceZoneForwarderOrderRead(ctx, d, m)...)
+}
+
+func resourceZoneForwarderOrderRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	server, zone := parseForwarderID(d.Id())
+	settings, err := c.ForwarderSettings(server, zone)
+	if err != nil {
+		if zone != "" && isZoneNotExistError(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(fmt.Errorf("failed to read forwarder settings: %w", err))
+	}
+
+	state := newStateSetter(d)
+	state.set("dns_server", server)
+	state.set("zone", zone)
+	state.set("forwarders", settings.Forwarders)
+	state.set("timeout", settings.Timeout)
+	state.set("forwarders_only", settings.ForwardersOnly)
+	return state.diags
+}
+
+func resourceZoneForwarderOrderUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if d.HasChanges("forwarders", "timeout", "forwarders_only") {
+		if diags = applyForwarderSettings(ctx, d, m); diags.HasError() {
+			return diags
+		}
+		diags = append(diags, setWriteMetadata(d, m)...)
+	}
+
+	return append(diags, resourceZoneForwarderOrderRead(ctx, d, m)...)
+}
+
+func resourceZoneForwarderOrderDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// A server without forwarders recurses itself, so the settings are left as they are rather than cleared
+	d.SetId("")
+	return nil
+}
+
+// buildForwarderID is the server for server-wide forwarders and server/zone for a conditional forwarder zone
+func buildForwarderID(server, zone string) string {
+	if zone == "" {
+		return server
+	}
+	return server + "/" + idComponent(zone)
+}
+
+// parseForwarderID is the inverse of buildForwarderID
+func parseForwarderID(id string) (server, zone string) {
+	server, zone, _ = strings.Cut(id, "/")
+	return server, fromIDComponent(zone)
+}
+
+// applyForwarderSettings writes the configured forwarder settings, keeping the server's timeout and
+// forwarders-only setting when they are left out
+// Backends that cannot write them pass when the server already forwards as configured
+func applyForwarderSettings(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	server := d.Get("dns_server").(string)
+	zone := d.Get("zone").(string)
+
+	live, err := c.ForwarderSettings(server, zone)
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to read forwarder settings: %w", err))
+	}
+
+	want := *live
+	want.Forwarders = nil
+	for _, v := range d.Get("forwarders").([]interface{}) {
+		want.Forwarders = append(want.Forwarders, v.(string))
+	}
+	// timeout and forwarders_only are Computed, so whether they are set is only visible in the raw configuration
+	if raw := d.GetRawConfig(); !raw.IsNull() {
+		if !raw.GetAttr("timeout").IsNull() {
+			want.Timeout = d.Get("timeout").(int)
+		}
+		if !raw.GetAttr("forwarders_only").IsNull() {
+			want.ForwardersOnly = d.Get("forwarders_only").(bool)
+		}
+	}
+
+	if want.equal(*live) {
+		return nil
+	}
+	if !c.canWriteForwarders() {
+		return diag.Diagnostics{{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Forwarder settings of %s differ from the configuration", forwarderTarget(server, zone)),
+			Detail: fmt.Sprintf("It forwards to %s (timeout %ds, forwarders only %t); the configuration wants %s (timeout %ds, forwarders only %t). "+
+				"samba-tool has no command for forwarder settings. On a Samba DC the forwarders come from smb.conf: "+
+				"set `dns forwarder = %s`, restart samba, then apply again.",
+				formatForwarders(live.Forwarders), live.Timeout, live.ForwardersOnly,
+				formatForwarders(want.Forwarders), want.Timeout, want.ForwardersOnly, strings.Join(want.Forwarders, " ")),
+		}}
+	}
+	if err := c.SetForwarderSettings(server, zone, want); err != nil {
+		return diag.FromErr(fmt.Errorf("failed to write forwarder settings of %s: %w", forwarderTarget(server, zone), err))
+	}
+	return nil
+}
+
+// forwarderTarget names the server or conditional forwarder zone in messages
+func forwarderTarget(server, zone string) string {
+	if zone == "" {
+		return server
+	}
+	return fmt.Sprintf("conditional forwarder zone %s on %s", zone, server)
+}
+
+// formatForwarders lists forwarders in messages
+func formatForwarders(addrs []string) string {
+	if len(addrs) == 0 {
+		return "no forwarders"
+	}
+	return strings.Join(addrs, ", ")
+}
@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceZoneSerialExample is the example configuration shown in the resource documentation
+const resourceZoneSerialExample = `
+resource "sambadns_zone_serial" "example" {
+  server = "dc01.example.com"
+  zone   = "example.com"
+
+  triggers = {
+    records = sha1(jsonencode([for r in sambadns_record.web : r.value]))
+  }
+
+  depends_on = [sambadns_record.web]
+}
+`
+
+func resourceZoneSerial() *schema.Resource {
+	return &schema.Resource{
+		Description: docDescription("Increments a zone's SOA serial on create and whenever `triggers` change, so secondaries that "+
+			"rely on serial changes pick up a batch of record changes. Destroying it leaves the zone untouched.",
+			resourceZoneSerialExample, ""),
+
+		CreateContext: wrapCRUD(resourceZoneSerialCreate),
+		ReadContext:   wrapCRUD(resourceZoneSerialRead),
+		UpdateContext: wrapCRUD(resourceZoneSerialUpdate),
+		DeleteContext: wrapCRUD(resourceZoneSerialDelete),
+
+		Schema: map[string]*schema.Schema{
+			"dns_server": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS server hostname (e.g., dns.example.com).",
+			},
+			"zone": {
+				Type:        schema.TypeString,
+				Required:    true,
+				ForceNew:    true,
+				Description: "DNS zone name (e.g., example.com).",
+			},
+			"triggers": {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values; any change bumps the serial again.",
+			},
+			// Computed attributes
+			"serial": {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Serial written by the last bump.",
+			},
+			"profile":        profileSchema(),
+			"credentials":    credentialsSchema(),
+			"retry":          resourceRetrySchema(),
+			"write_metadata": writeMetadataSchema(),
+		},
+	}
+}
+
+func resourceZoneSerialCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := bumpZoneSerial(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	d.SetId(fmt.Sprintf("%s/%s", d.Get("dns_server").(string), d.Get("zone").(string)))
+
+	diags = append(diags, setWriteMetadata(d, m)...)
+
+	return append(diags, resourceZoneSerialRead(ctx, d, m)...)
+}
+
+func resourceZoneSerialRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// The live serial moves with every record change, so it is only checked for the zone's existence;
+	// storing it would turn each unrelated update into drift
+	if _, err := c.QuerySOA(d.Get("dns_server").(string), d.Get("zone").(string)); err != nil {
+		if isNotExistError(err) {
+			d.SetId("")
+			return nil
+		}
+		return diag.FromErr(fmt.Errorf("failed to query SOA: %w", err))
+	}
+
+	return nil
+}
+
+func resourceZoneSerialUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if d.HasChange("triggers") {
+		if diags = bumpZoneSerial(ctx, d, m); diags.HasError() {
+			return diags
+		}
+		diags = append(diags, setWriteMetadata(d, m)...)
+	}
+
+	return append(diags, resourceZoneSerialRead(ctx, d, m)...)
+}
+
+func resourceZoneSerialDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Serials only move forward; there is nothing to undo
+	d.SetId("")
+	return nil
+}
+
+// bumpZoneSerial increments the serial and records the new value
+func bumpZoneSerial(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c, err := clientFor(ctx, d, m)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	serial, err := c.BumpSerial(d.Get("dns_server").(string), d.Get("zone").(string))
+	if err != nil {
+		return diag.FromErr(fmt.Errorf("failed to bump SOA serial: %w", err))
+	}
+
+	state := newStateSetter(d)
+	state.set("serial", int(serial))
+	return state.diags
+}
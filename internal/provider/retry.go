@@ -0,0 +1,218 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// retryableMarkers are samba-tool error fragments that indicate a transient failure
+var retryableMarkers = []string{
+	"NT_STATUS_IO_TIMEOUT",
+	"NT_STATUS_CONNECTION_REFUSED",
+	"NT_STATUS_CONNECTION_RESET",
+	"NT_STATUS_CONNECTION_DISCONNECTED",
+	"NT_STATUS_HOST_UNREACHABLE",
+	"NT_STATUS_NETWORK_UNREACHABLE",
+	"NT_STATUS_INVALID_NETWORK_RESPONSE",
+	"WERR_RPC_S_SERVER_UNAVAILABLE",
+	"Connection refused",
+	"timed out",
+}
+
+var ntStatusRegex = regexp.MustCompile(`\b(NT_STATUS_[A-Z_]+|WERR_[A-Z_]+)\b`)
+
+// retryPolicy controls how transient samba-tool failures are retried
+type retryPolicy struct {
+	Attempts   int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// ClockSkew also retries Kerberos clock skew failures
+	ClockSkew bool
+}
+
+// retrySchema returns the retry block used to configure a retryPolicy
+func retrySchema(description string) *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeList,
+		Optional:    true,
+		MaxItems:    1,
+		Description: description,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"attempts": {
+					Type:         schema.TypeInt,
+					Optional:     true,
+					Default:      3,
+					ValidateFunc: validation.IntAtLeast(0),
+					Description:  "Number of retries after the first failed attempt.",
+				},
+				"min_backoff": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "1s",
+					ValidateFunc: validateDuration,
+					Description:  "Delay before the first retry (e.g., `500ms`, `2s`). Doubles on each retry.",
+				},
+				"max_backoff": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					Default:      "30s",
+					ValidateFunc: validateDuration,
+					Description:  "Upper bound for the delay between retries.",
+				},
+				"clock_skew": {
+					Type:        schema.TypeBool,
+					Optional:    true,
+					Default:     false,
+					Description: "Also retry Kerberos clock skew failures, e.g. on freshly booted runners whose clock NTP is still stepping.",
+				},
+			},
+		},
+	}
+}
+
+// resourceRetrySchema returns the per-resource retry block that replaces the provider retry policy
+func resourceRetrySchema() *schema.Schema {
+	return retrySchema("Retry policy for this resource's samba-tool calls, replacing the provider `retry` block as a whole. " +
+		"Use it for records in zones hosted on slow or flaky DCs, so the rest of the apply keeps the provider policy.")
+}
+
+// withResourceRetry returns c with the retry policy of the resource's retry block, or c when it has none
+func withResourceRetry(c *SambaClient, d resourceGetter) *SambaClient {
+	raw, _ := d.Get("retry").([]interface{})
+	if policy, ok := expandRetryPolicy(raw); ok {
+		return c.withRetryPolicy(policy)
+	}
+	return c
+}
+
+// expandRetryPolicy reads a retry block, returning ok=false when the block is absent
+func expandRetryPolicy(raw []interface{}) (policy retryPolicy, ok bool) {
+	if len(raw) == 0 || raw[0] == nil {
+		return retryPolicy{}, false
+	}
+	cfg := raw[0].(map[string]interface{})
+	// Durations were validated by the schema
+	minBackoff, _ := time.ParseDuration(cfg["min_backoff"].(string))
+	maxBackoff, _ := time.ParseDuration(cfg["max_backoff"].(string))
+	return retryPolicy{
+		Attempts:   cfg["attempts"].(int),
+		MinBackoff: minBackoff,
+		MaxBackoff: maxBackoff,
+		ClockSkew:  cfg["clock_skew"].(bool),
+	}, true
+}
+
+// validateDuration checks that a string parses as a Go duration
+func validateDuration(v interface{}, k string) (warnings []string, errs []error) {
+	if _, err := time.ParseDuration(v.(string)); err != nil {
+		errs = append(errs, fmt.Errorf("%q must be a duration like 500ms or 2s: %w", k, err))
+	}
+	return warnings, errs
+}
+
+// backoff returns the delay before the given retry (0-based), doubling from MinBackoff up to MaxBackoff
+func (p retryPolicy) backoff(retry int) time.Duration {
+	delay := p.MinBackoff
+	for i := 0; i < retry && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// retryLog records the retries performed during one Terraform operation
+type retryLog struct {
+	mu      sync.Mutex
+	reasons []string
+}
+
+// add records one retry and its reason
+func (l *retryLog) add(reason string) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.reasons = append(l.reasons, reason)
+}
+
+// summary describes the recorded retries, e.g. "3 retries (NT_STATUS_IO_TIMEOUT x2, Connection refused)"
+func (l *retryLog) summary() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.reasons) == 0 {
+		return ""
+	}
+
+	counts := make(map[string]int)
+	for _, r := range l.reasons {
+		counts[r]++
+	}
+	var parts []string
+	for _, r := range sortedKeys(counts) {
+		if counts[r] > 1 {
+			parts = append(parts, fmt.Sprintf("%s x%d", r, counts[r]))
+		} else {
+			parts = append(parts, r)
+		}
+	}
+
+	noun := "retries"
+	if len(l.reasons) == 1 {
+		noun = "retry"
+	}
+	return fmt.Sprintf("%d %s (%s)", len(l.reasons), noun, strings.Join(parts, ", "))
+}
+
+// retryReason returns why a samba-tool failure is worth retrying, or "" when it is not
+func retryReason(stderr string) string {
+	for _, marker := range retryableMarkers {
+		if strings.Contains(stderr, marker) {
+			if m := ntStatusRegex.FindString(stderr); m != "" {
+				return m
+			}
+			return marker
+		}
+	}
+	return ""
+}
+
+type retryLogKey struct{}
+
+// retryLogFrom returns the retry log attached to the context, if any
+func retryLogFrom(ctx context.Context) *retryLog {
+	log, _ := ctx.Value(retryLogKey{}).(*retryLog)
+	return log
+}
+
+// reportRetries adds the retries recorded in log to diags: appended to the final
+// error diagnostic, or as a warning when the operation succeeded
+func reportRetries(log *retryLog, diags diag.Diagnostics) diag.Diagnostics {
+	summary := log.summary()
+	if summary == "" {
+		return diags
+	}
+
+	for i := len(diags) - 1; i >= 0; i-- {
+		if diags[i].Severity == diag.Error {
+			diags[i].Detail = strings.TrimSpace(diags[i].Detail + "\n\nsamba-tool was retried " + summary + " before giving up.")
+			return diags
+		}
+	}
+	return append(diags, diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  "samba-tool operations were retried",
+		Detail:   fmt.Sprintf("The operation succeeded after %s. The DNS server may be flaky.", summary),
+	})
+}
@@ -0,0 +1,309 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// commandRunner executes a samba-tool invocation
+// command is the argv prefix (samba-tool or a container exec), args the samba-tool
+// arguments and auth the authentication and --option arguments, kept apart so recordings
+// never hold secrets and replay regardless of connection settings
+type commandRunner interface {
+	run(command, args, auth []string) (stdout, stderr string, err error)
+}
+
+// lineRunner is implemented by runners that can stream stdout line by line
+// Errors returned by fn are passed back wrapped in lineError
+type lineRunner interface {
+	runLines(command, args, auth []string, fn func(line string) error) (stderr string, err error)
+}
+
+// lineError carries an error returned by a line callback, as opposed to a samba-tool failure
+type lineError struct {
+	err error
+}
+
+func (e lineError) Error() string { return e.err.Error() }
+
+// maxOutputLine bounds a single line of samba-tool output; long TXT records produce multi-kilobyte lines
+const maxOutputLine = 16 << 20
+
+// scanLines calls fn for every line read from r
+func scanLines(r io.Reader, fn func(line string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxOutputLine)
+	for scanner.Scan() {
+		if err := fn(scanner.Text()); err != nil {
+			return lineError{err}
+		}
+	}
+	return scanner.Err()
+}
+
+// execRunner runs samba-tool as a local process
+type execRunner struct {
+	// maxOutput bounds the stdout buffered by run, in bytes; 0 is unlimited
+	// runLines hands lines on as they arrive and is not bounded
+	maxOutput int64
+}
+
+func (r execRunner) run(command, args, auth []string) (string, string, error) {
+	argv := append([]string{}, command[1:]...)
+	argv = append(argv, args...)
+	argv = append(argv, auth...)
+
+	cmd := exec.Command(command[0], argv...)
+	cmd.Env = utf8Environ()
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	pipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", "", err
+	}
+
+	stdout := &limitedBuffer{limit: r.maxOutput}
+	if _, copyErr := io.Copy(stdout, pipe); copyErr == errOutputLimit {
+		// Stop samba-tool rather than buffering output nobody will keep; closing the pipe
+		// also stops any child still writing to it, such as a container exec
+		pipe.Close()
+		cmd.Process.Kill()
+	}
+	err = cmd.Wait()
+	if stdout.truncated {
+		what := strings.Join(args, " ")
+		if len(args) > 2 {
+			what = strings.Join(args[:2], " ")
+		}
+		return "", stderr.String(), fmt.Errorf("samba-tool %s printed more than the %d MiB max_output_size_mb allows and was stopped; "+
+			"raise max_output_size_mb if this output is legitimate (zone enumerations stream their output and are not limited)",
+			what, r.maxOutput>>20)
+	}
+	return stdout.buf.String(), stderr.String(), err
+}
+
+// utf8Environ is the environment samba-tool runs with: the provider's, with Python's UTF-8 mode on
+// Under a C or POSIX locale Python would otherwise decode arguments and encode output as ASCII,
+// mangling non-ASCII TXT values on their way in and out
+func utf8Environ() []string {
+	return append(os.Environ(), "PYTHONUTF8=1", "PYTHONIOENCODING=utf-8")
+}
+
+// errOutputLimit stops copying output that outgrew a limitedBuffer
+var errOutputLimit = errors.New("output limit exceeded")
+
+// limitedBuffer collects output up to limit bytes; 0 is unlimited
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && int64(b.buf.Len()+len(p)) > b.limit {
+		b.truncated = true
+		return 0, errOutputLimit
+	}
+	return b.buf.Write(p)
+}
+
+// withOutputLimit returns runner with its buffered output bounded to limit bytes
+// Only runners that start samba-tool are affected; replays and the file backend are not
+func withOutputLimit(runner commandRunner, limit int64) commandRunner {
+	switch r := runner.(type) {
+	case execRunner:
+		r.maxOutput = limit
+		return r
+	case recordRunner:
+		r.next = withOutputLimit(r.next, limit)
+		return r
+	}
+	return runner
+}
+
+func (execRunner) runLines(command, args, auth []string, fn func(line string) error) (string, error) {
+	argv := append([]string{}, command[1:]...)
+	argv = append(argv, args...)
+	argv = append(argv, auth...)
+
+	cmd := exec.Command(command[0], argv...)
+	cmd.Env = utf8Environ()
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", err
+	}
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+
+	scanErr := scanLines(stdout, fn)
+	if scanErr != nil {
+		// Stop samba-tool rather than draining output nobody will read
+		cmd.Process.Kill()
+	}
+	waitErr := cmd.Wait()
+
+	if scanErr != nil {
+		var parseErr lineError
+		if errors.As(scanErr, &parseErr) {
+			return stderr.String(), scanErr
+		}
+	}
+	if waitErr != nil {
+		return stderr.String(), waitErr
+	}
+	return stderr.String(), scanErr
+}
+
+// checkCommandAvailable verifies that the programs a runner will execute exist on this host
+// Hosted runners such as Terraform Cloud workers ship without samba-tool, and without this check
+// the first resource would fail with a bare "exec: not found"
+func checkCommandAvailable(command []string, runner commandRunner) error {
+	switch runner.(type) {
+	case replayRunner, *fileRunner:
+		return nil
+	}
+
+	path, err := exec.LookPath(command[0])
+	if err != nil {
+		if command[0] != "samba-tool" {
+			return fmt.Errorf("the container block runs samba-tool through %s, which was not found in PATH (%s). "+
+				"Install the %s CLI on the host running Terraform, or remove the container block to run samba-tool directly.",
+				command[0], os.Getenv("PATH"), command[0])
+		}
+		return fmt.Errorf("samba-tool was not found in PATH (%s). This provider has no pure-Go backend: every operation runs samba-tool. "+
+			"Install the package that ships it (samba-common-bin on Debian and Ubuntu) on the host running Terraform, "+
+			"use a custom agent image that includes it, or set a container block to run samba-tool inside the DC container.",
+			os.Getenv("PATH"))
+	}
+	if command[0] != "samba-tool" {
+		// samba-tool runs inside the container, where it cannot be checked without a round trip
+		return nil
+	}
+
+	// samba-tool is a Python script; a missing interpreter otherwise surfaces as a confusing "no such file"
+	if interpreter := scriptInterpreter(path); interpreter != "" {
+		if _, err := os.Stat(interpreter); err != nil {
+			return fmt.Errorf("samba-tool was found at %s, but its interpreter %s is missing. Install Python 3 and the Samba Python bindings (python3-samba on Debian and Ubuntu).",
+				path, interpreter)
+		}
+	}
+	return nil
+}
+
+// scriptInterpreter returns the interpreter named by a script's #! line, or "" when there is none
+// An "/usr/bin/env python3" line names env, which is as far as the check goes
+func scriptInterpreter(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return ""
+	}
+	if !strings.HasPrefix(line, "#!") {
+		return ""
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// recordRunner runs samba-tool and saves each invocation's output under dir for later replay
+type recordRunner struct {
+	dir  string
+	next commandRunner
+}
+
+func (r recordRunner) run(command, args, auth []string) (string, string, error) {
+	stdout, stderr, err := r.next.run(command, args, auth)
+
+	base := filepath.Join(r.dir, replayKey(args))
+	if writeErr := os.WriteFile(base+".out", []byte(stdout), 0o644); writeErr != nil {
+		return "", "", fmt.Errorf("failed to record samba-tool output: %w", writeErr)
+	}
+	if err != nil {
+		if writeErr := os.WriteFile(base+".err", []byte(stderr), 0o644); writeErr != nil {
+			return "", "", fmt.Errorf("failed to record samba-tool output: %w", writeErr)
+		}
+	} else {
+		os.Remove(base + ".err")
+	}
+
+	return stdout, stderr, err
+}
+
+// replayRunner answers invocations from outputs captured by recordRunner, without running samba-tool
+// An invocation with a .err file fails with its content as stderr
+type replayRunner struct {
+	dir string
+}
+
+func (r replayRunner) run(command, args, auth []string) (string, string, error) {
+	base := filepath.Join(r.dir, replayKey(args))
+
+	stdout, err := os.ReadFile(base + ".out")
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", "no replay fixture " + base + ".out", fmt.Errorf("replay: no recording for samba-tool %s", strings.Join(args, " "))
+		}
+		return "", "", err
+	}
+
+	stderr, err := os.ReadFile(base + ".err")
+	if err == nil {
+		return string(stdout), string(stderr), errors.New("replay: recorded failure")
+	}
+	return string(stdout), "", nil
+}
+
+// replayKey turns samba-tool arguments into a file name
+// Arguments are joined with "_"; bytes outside [A-Za-z0-9.@-] are percent-encoded so
+// wildcards, spaces and quotes in values stay unambiguous and portable
+func replayKey(args []string) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		var b strings.Builder
+		for j := 0; j < len(arg); j++ {
+			c := arg[j]
+			if c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '@' || c == '-' {
+				b.WriteByte(c)
+			} else {
+				fmt.Fprintf(&b, "%%%02X", c)
+			}
+		}
+		parts[i] = b.String()
+	}
+	return strings.Join(parts, "_")
+}
+
+// runnerFromEnv selects the runner for the provider
+// SAMBADNS_REPLAY_DIR replays recorded outputs, SAMBADNS_RECORD_DIR records real ones
+func runnerFromEnv() commandRunner {
+	if dir := os.Getenv("SAMBADNS_REPLAY_DIR"); dir != "" {
+		return replayRunner{dir: dir}
+	}
+	if dir := os.Getenv("SAMBADNS_RECORD_DIR"); dir != "" {
+		return recordRunner{dir: dir, next: execRunner{}}
+	}
+	return execRunner{}
+}
@@ -1,20 +1,47 @@
 package provider
 
 import (
-	"bytes"
+	"errors"
 	"fmt"
-	"os/exec"
-	"regexp"
-	"strconv"
+	"math"
+	"sort"
 	"strings"
+	"time"
 )
 
 // SambaClient wraps samba-tool DNS operations
 type SambaClient struct {
 	Username string
 	Password string
+	Ccache   string
+	// Realm is passed as --realm when set, for profiles in another forest
+	Realm string
+	// Command is the argv used to invoke samba-tool, e.g. a docker exec prefix
+	Command []string
+	Retry   retryPolicy
+	// Options are smb.conf settings passed to every invocation as --option
+	Options map[string]string
+
+	runner  commandRunner
+	retries *retryLog
+	latency *latencyStats
+	writes  *writeCounter
+	info    *infoCache
+	queries *queryCache
+	pool    *dcPool
+	logons  *credentialLatch
+	// manifest records successful writes when a signed manifest is configured
+	manifest *mutationManifest
+	// window refuses destructive writes outside the maintenance window
+	window *maintenanceWindow
+	// serials compares zone serials around record writes when serial_check is on
+	serials        *serialCheck
+	serialWarnings *serialWarnings
 }
 
+// maxTTL is the largest TTL DNS allows (RFC 2181 section 8)
+const maxTTL = math.MaxInt32
+
 // DNSRecord represents a DNS record
 type DNSRecord struct {
 	Server string
@@ -23,6 +50,10 @@ type DNSRecord struct {
 	Type   string
 	Value  string
 	TTL    int
+	// Timestamp is when a dynamic record was last registered or refreshed, zero for static records
+	Timestamp time.Time
+	// Flags is the record's dwFlags as samba-tool prints it; see record_flags.go
+	Flags uint32
 }
 
 // NewSambaClient creates a new samba-tool client
@@ -30,113 +61,393 @@ func NewSambaClient(username, password string) *SambaClient {
 	return &SambaClient{
 		Username: username,
 		Password: password,
+		Command:  []string{"samba-tool"},
+		runner:   runnerFromEnv(),
+		latency:  &latencyStats{},
+		writes:   &writeCounter{},
+		info:     newInfoCache(),
+		queries:  newQueryCache(),
+		logons:   newCredentialLatch(),
 	}
 }
 
-// authArgs returns the authentication arguments for samba-tool
+// withCredentials returns a copy of the client authenticating as another user
+func (c *SambaClient) withCredentials(username, password string) *SambaClient {
+	clone := *c
+	clone.Username = username
+	clone.Password = password
+	clone.Ccache = ""
+	return &clone
+}
+
+// withCcache returns a copy of the client authenticating from a Kerberos credential cache
+func (c *SambaClient) withCcache(ccache string) *SambaClient {
+	clone := *c
+	clone.Username = ""
+	clone.Password = ""
+	clone.Ccache = ccache
+	return &clone
+}
+
+// withRetryPolicy returns a copy of the client that retries according to policy
+func (c *SambaClient) withRetryPolicy(policy retryPolicy) *SambaClient {
+	clone := *c
+	clone.Retry = policy
+	return &clone
+}
+
+// withRetryLog returns a copy of the client that records its retries in log
+func (c *SambaClient) withRetryLog(log *retryLog) *SambaClient {
+	clone := *c
+	clone.retries = log
+	return &clone
+}
+
+// authArgs returns the authentication and connection option arguments for samba-tool
 func (c *SambaClient) authArgs() []string {
-	return []string{"-U", fmt.Sprintf("%s%%%s", c.Username, c.Password)}
+	var args []string
+	if c.Ccache != "" {
+		args = []string{"--use-kerberos=required", "--krb5-ccache=" + c.Ccache}
+	} else {
+		args = []string{"-U", fmt.Sprintf("%s%%%s", c.Username, c.Password)}
+	}
+	if c.Realm != "" {
+		args = append(args, "--realm="+c.Realm)
+	}
+
+	keys := make([]string, 0, len(c.Options))
+	for k := range c.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, fmt.Sprintf("--option=%s=%s", k, c.Options[k]))
+	}
+	return args
 }
 
 // runCommand executes samba-tool with the given arguments
+// Record queries are answered from the query cache when nothing was written since the same query ran
+// Operations for a DC whose circuit breaker is open run against another DC of the pool
 func (c *SambaClient) runCommand(args ...string) (string, error) {
-	fullArgs := append(args, c.authArgs()...)
-	cmd := exec.Command("samba-tool", fullArgs...)
+	args, server := c.routeArgs(args)
+	run := func() (string, error) {
+		output, err := c.execCommand(args)
+		c.pool.report(server, err)
+		return output, err
+	}
+	if isCachedQuery(args) {
+		return c.queries.get(c.queryKey(args), c.writes.value(), run)
+	}
+	return run()
+}
 
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+// execCommand runs samba-tool once the arguments are final
+// Transient failures are retried according to the client's retry policy
+func (c *SambaClient) execCommand(args []string) (string, error) {
+	runner := c.runner
+	if runner == nil {
+		runner = execRunner{}
+	}
 
-	err := cmd.Run()
-	if err != nil {
-		// Include stderr in error message for debugging
-		return "", fmt.Errorf("samba-tool error: %v, stderr: %s", err, stderr.String())
+	if err := c.logons.check(c); err != nil {
+		return "", err
+	}
+	if err := c.window.check(args); err != nil {
+		return "", err
+	}
+	// A zone whose serial cannot be read before the write is not checked; the write reports why
+	zone, checked := c.checkedZone(args)
+	var serial string
+	if checked {
+		var err error
+		if serial, err = c.zoneSerial(args[2], zone); err != nil {
+			checked = false
+		}
+	}
+	if isWriteCommand(args) {
+		c.writes.add()
+	}
+
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		stdout, stderr, err := runner.run(c.Command, args, c.authArgs())
+		c.latency.record(time.Since(start))
+		if err == nil {
+			if isWriteCommand(args) {
+				if err := c.manifest.record(c.identity(), args); err != nil {
+					return stdout, fmt.Errorf("samba-tool %s succeeded, but %w", strings.Join(args[:2], " "), err)
+				}
+			}
+			if checked {
+				if err := c.verifySerial(args, serial); err != nil {
+					return stdout, err
+				}
+			}
+			return stdout, nil
+		}
+
+		reason := c.retryReason(stderr)
+		if reason == "" || attempt >= c.Retry.Attempts {
+			return "", c.commandError(args, err, stderr)
+		}
+
+		c.retries.add(reason)
+		time.Sleep(c.Retry.backoff(attempt))
 	}
+}
 
-	return stdout.String(), nil
+// streamCommand executes samba-tool and hands stdout to fn line by line, without buffering it whole
+// An invocation is only retried while no line has been delivered yet
+func (c *SambaClient) streamCommand(fn func(line string) error, args ...string) (err error) {
+	args, server := c.routeArgs(args)
+	runner := c.runner
+	if runner == nil {
+		runner = execRunner{}
+	}
+	lines, ok := runner.(lineRunner)
+	if !ok {
+		// runCommand reports to the pool itself
+		output, err := c.runCommand(args...)
+		if err != nil {
+			return err
+		}
+		err = scanLines(strings.NewReader(output), fn)
+		var parseErr lineError
+		if errors.As(err, &parseErr) {
+			return parseErr.err
+		}
+		return err
+	}
+	defer func() { c.pool.report(server, err) }()
+
+	if err := c.logons.check(c); err != nil {
+		return err
+	}
+	if isWriteCommand(args) {
+		c.writes.add()
+	}
+
+	for attempt := 0; ; attempt++ {
+		delivered := false
+		start := time.Now()
+		stderr, err := lines.runLines(c.Command, args, c.authArgs(), func(line string) error {
+			delivered = true
+			return fn(line)
+		})
+		c.latency.record(time.Since(start))
+		if err == nil {
+			return nil
+		}
+		var parseErr lineError
+		if errors.As(err, &parseErr) {
+			return parseErr.err
+		}
+
+		reason := c.retryReason(stderr)
+		if delivered || reason == "" || attempt >= c.Retry.Attempts {
+			return c.commandError(args, err, stderr)
+		}
+
+		c.retries.add(reason)
+		time.Sleep(c.Retry.backoff(attempt))
+	}
+}
+
+// retryReason returns why a failure is worth retrying under the client's policy, or ""
+func (c *SambaClient) retryReason(stderr string) string {
+	if reason := retryReason(stderr); reason != "" {
+		return reason
+	}
+	if c.Retry.ClockSkew && isClockSkew(stderr) {
+		return "clock skew"
+	}
+	return ""
+}
+
+// commandError builds the error of a failed samba-tool invocation
+// The stderr suffix is kept last, since failure debouncing keys on it
+func (c *SambaClient) commandError(args []string, err error, stderr string) error {
+	if latched := c.logons.observe(c, stderr); latched != nil {
+		return latched
+	}
+	if isClockSkew(stderr) && len(args) >= 3 && args[0] == "dns" {
+		return fmt.Errorf("%s: samba-tool error: %v, stderr: %s", c.explainClockSkew(args[2]), err, stderr)
+	}
+	// Include stderr in error message for debugging
+	return fmt.Errorf("samba-tool error: %v, stderr: %s", err, stderr)
+}
+
+// errTombstoned marks a write that collided with a tombstoned dnsNode
+var errTombstoned = errors.New("record collides with a tombstoned DNS node; wait for the DC to scavenge it " +
+	"(dns serverinfo shows the tombstone interval), or remove the stale node with samba-tool dns delete using its old value, then apply again")
+
+// isNotExistError reports whether a samba-tool error means the name or record does not exist
+func isNotExistError(err error) bool {
+	return strings.Contains(err.Error(), "WERR_DNS_ERROR_NAME_DOES_NOT_EXIST") ||
+		strings.Contains(err.Error(), "WERR_DNS_ERROR_RECORD_DOES_NOT_EXIST") ||
+		strings.Contains(err.Error(), "does not exist")
 }
 
 // CreateRecord creates a DNS record
 func (c *SambaClient) CreateRecord(r DNSRecord) error {
-	args := []string{"dns", "add", r.Server, r.Zone, r.Name, r.Type, r.Value}
-	_, err := c.runCommand(args...)
+	_, err := c.createRecord(r)
+	return err
+}
+
+// createRecord is CreateRecord that also reports whether the record was added
+// changed is false when an identical record already existed
+func (c *SambaClient) createRecord(r DNSRecord) (changed bool, err error) {
+	r.Type = strings.ToUpper(r.Type)
+	value := r.Value
+	if r.Type == "TXT" {
+		if err := validateTXTBytes(value); err != nil {
+			return false, err
+		}
+		value = txtArgument(value)
+	}
+	args := []string{"dns", "add", r.Server, r.Zone, r.Name, r.Type, value}
+	_, err = c.runCommand(args...)
 	if err != nil {
 		// Check if record already exists
 		if strings.Contains(err.Error(), "already exist") {
-			// Record exists - check if value matches
+			// Record exists - check if value matches, using the same rules as diff suppression
 			existing, queryErr := c.QueryRecord(r.Server, r.Zone, r.Name, r.Type)
-			if queryErr == nil && existing != nil && existing.Value == r.Value {
+			if queryErr != nil {
+				// Without the read-back the conflict cannot be classified
+				return false, fmt.Errorf("%s %s in zone %s already exists, and reading it back failed: %w", r.Name, r.Type, r.Zone, queryErr)
+			}
+			if existing != nil && recordValuesEqual(r.Type, existing.Value, r.Value) {
 				// Same value, idempotent success
-				return nil
+				return false, nil
+			}
+			if existing == nil {
+				// The add conflicts with a record no query can see: a tombstoned node
+				return false, fmt.Errorf("%w: %s %s in zone %s is reported as existing but cannot be read back", errTombstoned, r.Name, r.Type, r.Zone)
 			}
-			return fmt.Errorf("record already exists with different value")
+			return false, fmt.Errorf("record already exists with different value")
 		}
-		return err
+		return false, err
 	}
-	return nil
+	return true, nil
 }
 
 // QueryRecord reads a DNS record
 func (c *SambaClient) QueryRecord(server, zone, name, recordType string) (*DNSRecord, error) {
+	record, _, err := c.QueryRecordRaw(server, zone, name, recordType)
+	return record, err
+}
+
+// QueryRecordRaw is QueryRecord that also returns the unparsed samba-tool output
+// The output is empty when the record does not exist
+func (c *SambaClient) QueryRecordRaw(server, zone, name, recordType string) (*DNSRecord, string, error) {
+	recordType = strings.ToUpper(recordType)
 	args := []string{"dns", "query", server, zone, name, recordType}
 	output, err := c.runCommand(args...)
 	if err != nil {
-		if strings.Contains(err.Error(), "WERR_DNS_ERROR_NAME_DOES_NOT_EXIST") ||
-			strings.Contains(err.Error(), "WERR_DNS_ERROR_RECORD_DOES_NOT_EXIST") ||
-			strings.Contains(err.Error(), "does not exist") {
-			return nil, nil // Record does not exist
+		if isNotExistError(err) {
+			return nil, "", nil // Record does not exist
 		}
-		return nil, err
+		return nil, "", err
 	}
 
 	record, parseErr := parseQueryOutput(output, server, zone, name, recordType)
 	if parseErr != nil {
-		return nil, parseErr
-	}
-	if record != nil {
+		return nil, output, parseErr
 	}
-	return record, nil
+	return record, output, nil
+}
+
+// QueryName reads every record stored at a name (samba-tool type ALL)
+// Returns nil when the name does not exist
+func (c *SambaClient) QueryName(server, zone, name string) ([]DNSRecord, error) {
+	records, _, err := c.QueryNameRaw(server, zone, name)
+	return records, err
 }
 
-// formatTXTForDelete converts TXT value from query format to delete format
-// Query returns: "string1","string2"
-// Delete needs:  'string1' 'string2'
-func formatTXTForDelete(value string) string {
-	// Split on ","
-	parts := strings.Split(value, ",")
-	var result []string
-	for _, part := range parts {
-		// Remove surrounding double quotes and add single quotes
-		part = strings.TrimSpace(part)
-		part = strings.Trim(part, "\"")
-		result = append(result, "'"+part+"'")
+// QueryNameRaw is QueryName that also returns the unparsed samba-tool output
+// The output is empty when the name does not exist
+func (c *SambaClient) QueryNameRaw(server, zone, name string) ([]DNSRecord, string, error) {
+	output, err := c.runCommand("dns", "query", server, zone, name, "ALL")
+	if err != nil {
+		if isNotExistError(err) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+	records, parseErr := parseNodeRecords(output, server, zone, name)
+	if parseErr != nil {
+		return nil, output, parseErr
 	}
-	return strings.Join(result, " ")
+	return records, output, nil
 }
 
-// DeleteRecord removes a DNS record
-func (c *SambaClient) DeleteRecord(r DNSRecord) error {
-	value := r.Value
+// ServerInfo returns the fields reported by samba-tool dns serverinfo
+// The result is fetched once per provider run
+func (c *SambaClient) ServerInfo(server string) (map[string]string, error) {
+	return c.info.get(serverInfoKey(server), func() (map[string]string, error) {
+		output, err := c.runCommand("dns", "serverinfo", server)
+		if err != nil {
+			return nil, err
+		}
+		return parseKeyValueOutput(output), nil
+	})
+}
 
-	// TXT records need special formatting for delete
-	if strings.ToUpper(r.Type) == "TXT" && strings.Contains(value, ",") {
-		value = formatTXTForDelete(value)
+// ZoneInfo returns the fields reported by samba-tool dns zoneinfo
+// The result is fetched once per provider run, and again after the provider creates or deletes the zone
+func (c *SambaClient) ZoneInfo(server, zone string) (map[string]string, error) {
+	return c.info.get(zoneInfoKey(server, zone), func() (map[string]string, error) {
+		output, err := c.runCommand("dns", "zoneinfo", server, zone)
+		if err != nil {
+			return nil, err
+		}
+		return parseKeyValueOutput(output), nil
+	})
+}
+
+// parseKeyValueOutput parses "key : value" lines as printed by serverinfo and zoneinfo
+// Example output:
+//
+//	dwVersion                   : 0xece0205
+//	fDsAvailable                : TRUE
+func parseKeyValueOutput(output string) map[string]string {
+	fields := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		fields[key] = strings.TrimSpace(value)
 	}
+	return fields
+}
 
-	args := []string{"dns", "delete", r.Server, r.Zone, r.Name, r.Type, value}
+// DeleteRecord removes a DNS record
+func (c *SambaClient) DeleteRecord(r DNSRecord) error {
+	_, err := c.deleteRecord(r)
+	return err
+}
 
+// deleteRecord is DeleteRecord that also reports whether a record was removed
+// changed is false when the record did not exist
+func (c *SambaClient) deleteRecord(r DNSRecord) (changed bool, err error) {
+	r.Type = strings.ToUpper(r.Type)
+	args := []string{"dns", "delete", r.Server, r.Zone, r.Name, r.Type, deleteValue(r.Type, r.Value)}
 
-	_, err := c.runCommand(args...)
+	_, err = c.runCommand(args...)
 	if err != nil {
 		// If record doesn't exist, treat as success
-		if strings.Contains(err.Error(), "WERR_DNS_ERROR_NAME_DOES_NOT_EXIST") ||
-			strings.Contains(err.Error(), "WERR_DNS_ERROR_RECORD_DOES_NOT_EXIST") ||
-			strings.Contains(err.Error(), "does not exist") {
-			return nil
+		if isNotExistError(err) {
+			return false, nil
 		}
-		return err
+		return false, err
 	}
-	return nil
+	return true, nil
 }
 
 // UpdateRecord updates a DNS record (delete + create)
@@ -169,56 +480,49 @@ func parseQueryOutput(output, server, zone, name, recordType string) (*DNSRecord
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
 		if strings.HasPrefix(line, typePrefix) {
-			// Parse: "CNAME: value (flags=..., serial=..., ttl=3600)"
-			// or "A: 192.168.1.1 (flags=..., serial=..., ttl=3600)"
-			// or "MX: mail.example.com. (10) (flags=f0, serial=0, ttl=900)"
-
-			// Extract value (between type: and opening paren)
-			afterType := strings.TrimPrefix(line, typePrefix)
-			afterType = strings.TrimSpace(afterType)
-
-			parenIdx := strings.Index(afterType, "(")
-			if parenIdx == -1 {
-				return nil, fmt.Errorf("unexpected output format: %s", line)
+			record, err := parseRecordLine(line)
+			if err != nil {
+				return nil, err
 			}
+			record.Server = server
+			record.Zone = zone
+			record.Name = name
+			return record, nil
+		}
+	}
 
-			value := strings.TrimSpace(afterType[:parenIdx])
-
-			// For MX records, extract priority from first (N) and append to value
-			// Format: "mail.example.com. (10) (flags=...)"
-			// Priority is the first parenthesized number
-			if strings.ToUpper(recordType) == "MX" {
-				// Match first (N) which is the priority
-				priRegex := regexp.MustCompile(`^\((\d+)\)`)
-				remaining := strings.TrimSpace(afterType[parenIdx:])
-				if matches := priRegex.FindStringSubmatch(remaining); len(matches) > 1 {
-					priority := matches[1]
-					// Remove trailing dot from hostname if present
-					value = strings.TrimSuffix(value, ".")
-					// Format: "hostname priority" for samba-tool delete
-					value = fmt.Sprintf("%s %s", value, priority)
-				}
-			}
+	return nil, fmt.Errorf("record type %s not found in output", recordType)
+}
 
-			// Extract TTL
-			ttl := 3600 // default
-			ttlRegex := regexp.MustCompile(`ttl=(\d+)`)
-			if matches := ttlRegex.FindStringSubmatch(afterType); len(matches) > 1 {
-				if parsed, err := strconv.Atoi(matches[1]); err == nil {
-					ttl = parsed
-				}
-			}
+// parseNodeRecords parses every record of the queried node from samba-tool dns query output
+// Records listed under child nodes (Name=child, ...) are skipped
+func parseNodeRecords(output, server, zone, name string) ([]DNSRecord, error) {
+	var records []DNSRecord
+	inNode := true
 
-			return &DNSRecord{
-				Server: server,
-				Zone:   zone,
-				Name:   name,
-				Type:   strings.ToUpper(recordType),
-				Value:  value,
-				TTL:    ttl,
-			}, nil
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "Name=") {
+			// The queried node is printed with an empty name, children with their label
+			nodeName, _ := parseNodeHeader(line)
+			inNode = nodeName == "" || nodeName == name
+			continue
 		}
+		if !inNode {
+			continue
+		}
+		record, err := parseRecordLine(line)
+		if err != nil {
+			return nil, err
+		}
+		record.Server = server
+		record.Zone = zone
+		record.Name = name
+		records = append(records, *record)
 	}
 
-	return nil, fmt.Errorf("record type %s not found in output", recordType)
+	return records, nil
 }
@@ -0,0 +1,231 @@
+package provider
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// valueToken is a lexical element of a samba-tool record line
+type valueToken struct {
+	kind  tokenKind
+	text  string // token text, without quotes or parentheses
+	start int    // byte offset of the token in the input
+	end   int    // byte offset just past the token
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenQuoted
+	tokenGroup
+)
+
+// tokenizeRecordValue splits the part of a record line after "TYPE:" into words,
+// double-quoted strings and parenthesized groups
+// Parentheses inside quoted strings do not open groups, and quotes inside groups are kept verbatim
+func tokenizeRecordValue(s string) ([]valueToken, error) {
+	var tokens []valueToken
+
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == ',':
+			i++
+
+		case c == '"':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' && j+1 < len(s) {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated quoted string at offset %d", i)
+			}
+			tokens = append(tokens, valueToken{kind: tokenQuoted, text: b.String(), start: i, end: j + 1})
+			i = j + 1
+
+		case c == '(':
+			depth := 0
+			inQuote := false
+			j := i
+			for ; j < len(s); j++ {
+				switch {
+				case s[j] == '"':
+					inQuote = !inQuote
+				case inQuote:
+				case s[j] == '(':
+					depth++
+				case s[j] == ')':
+					depth--
+				}
+				if depth == 0 {
+					break
+				}
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unbalanced parenthesis at offset %d", i)
+			}
+			tokens = append(tokens, valueToken{kind: tokenGroup, text: s[i+1 : j], start: i, end: j + 1})
+			i = j + 1
+
+		default:
+			// A word runs until whitespace; parentheses or quotes inside it stay part of the word,
+			// so hostnames such as "host(1).example.com" survive intact
+			j := i
+			for j < len(s) && s[j] != ' ' && s[j] != '\t' {
+				j++
+			}
+			tokens = append(tokens, valueToken{kind: tokenWord, text: s[i:j], start: i, end: j})
+			i = j
+		}
+	}
+
+	return tokens, nil
+}
+
+// parseMetadataGroup parses "flags=f0, serial=2, ttl=900" into its fields
+// ok is false when the group does not look like samba-tool record metadata
+func parseMetadataGroup(group string) (fields map[string]string, ok bool) {
+	fields = make(map[string]string)
+	for _, field := range strings.Split(group, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			return nil, false
+		}
+		fields[key] = value
+	}
+	_, hasTTL := fields["ttl"]
+	_, hasFlags := fields["flags"]
+	return fields, hasTTL || hasFlags
+}
+
+// parseRecordLine parses a single record line of samba-tool dns query output
+// Parse: "CNAME: value (flags=..., serial=..., ttl=3600)"
+// or "A: 192.168.1.1 (flags=..., serial=..., ttl=3600)"
+// or "MX: mail.example.com. (10) (flags=f0, serial=0, ttl=900)"
+// or "SRV: dc1.example.com. (389, 0, 100) (flags=f0, serial=0, ttl=900)"
+// or "TXT: "v=spf1 (mx)","second" (flags=f0, serial=0, ttl=900)"
+// or "A: 192.168.1.50 (flags=f0, serial=7, ttl=1200, timestamp=3719304)" for a dynamic record
+func parseRecordLine(line string) (*DNSRecord, error) {
+	recordType, afterType, found := strings.Cut(line, ":")
+	if !found {
+		return nil, fmt.Errorf("unexpected output format: %s", line)
+	}
+	recordType = strings.ToUpper(strings.TrimSpace(recordType))
+	if recordType == "" || strings.ContainsAny(recordType, " \t") {
+		return nil, fmt.Errorf("unexpected output format: %s", line)
+	}
+
+	tokens, err := tokenizeRecordValue(afterType)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected output format: %s: %w", line, err)
+	}
+
+	// The trailing group holds flags, serial, ttl and for dynamic records the timestamp;
+	// everything before it is the value
+	if len(tokens) == 0 || tokens[len(tokens)-1].kind != tokenGroup {
+		return nil, fmt.Errorf("unexpected output format: %s", line)
+	}
+	meta, ok := parseMetadataGroup(tokens[len(tokens)-1].text)
+	if !ok {
+		return nil, fmt.Errorf("unexpected output format: %s", line)
+	}
+	valueTokens := tokens[:len(tokens)-1]
+
+	var value string
+	if len(valueTokens) > 0 {
+		value = strings.TrimSpace(afterType[valueTokens[0].start:valueTokens[len(valueTokens)-1].end])
+	}
+
+	switch recordType {
+	case "MX":
+		// Format: "mail.example.com. (10)" becomes "mail.example.com 10" for samba-tool delete
+		if len(valueTokens) == 2 && valueTokens[1].kind == tokenGroup {
+			if priority, ok := numericFields(valueTokens[1].text, 1); ok {
+				value = fmt.Sprintf("%s %s", strings.TrimSuffix(valueTokens[0].text, "."), priority[0])
+			}
+		}
+	case "TXT":
+		// Stored bytes are printed as they are; state only holds UTF-8
+		value = escapeInvalidUTF8(value)
+	case "SRV":
+		// Format: "dc1.example.com. (389, 0, 100)" becomes "dc1.example.com 389 0 100",
+		// the target port priority weight order samba-tool add and delete expect
+		if len(valueTokens) == 2 && valueTokens[1].kind == tokenGroup {
+			if fields, ok := numericFields(valueTokens[1].text, 3); ok {
+				value = fmt.Sprintf("%s %s", strings.TrimSuffix(valueTokens[0].text, "."), strings.Join(fields, " "))
+			}
+		}
+	}
+
+	// Extract TTL
+	ttl := 3600 // default
+	if raw, found := meta["ttl"]; found {
+		if parsed, err := strconv.ParseUint(raw, 10, 32); err == nil {
+			// RFC 2181: a TTL with the most significant bit set is treated as zero
+			if parsed > maxTTL {
+				parsed = 0
+			}
+			ttl = int(parsed)
+		}
+	}
+
+	return &DNSRecord{
+		Type:      recordType,
+		Value:     value,
+		TTL:       ttl,
+		Timestamp: parseRecordTimestamp(meta["timestamp"]),
+		Flags:     parseRecordFlags(meta["flags"]),
+	}, nil
+}
+
+// dnsTimestampEpoch is the Unix time of 1601-01-01 UTC, from which DNS record timestamps count hours
+// The span exceeds what a time.Duration holds, so conversions go through Unix seconds
+const dnsTimestampEpoch = -11644473600
+
+// parseRecordTimestamp converts the timestamp field of record metadata to a time
+// Static records have timestamp 0 or none at all, and get the zero time
+func parseRecordTimestamp(raw string) time.Time {
+	hours, err := strconv.ParseUint(raw, 10, 32)
+	if err != nil || hours == 0 {
+		return time.Time{}
+	}
+	return time.Unix(dnsTimestampEpoch+int64(hours)*3600, 0).UTC()
+}
+
+// formatRecordTimestamp is the inverse of parseRecordTimestamp
+func formatRecordTimestamp(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	return uint64((t.Unix() - dnsTimestampEpoch) / 3600)
+}
+
+// recordAgeDays returns the whole days since a dynamic record was last refreshed, 0 for static records
+func recordAgeDays(r DNSRecord, now time.Time) int {
+	if r.Timestamp.IsZero() || now.Before(r.Timestamp) {
+		return 0
+	}
+	return int(now.Sub(r.Timestamp) / (24 * time.Hour))
+}
+
+// numericFields splits a comma separated group into exactly n unsigned integers
+func numericFields(group string, n int) ([]string, bool) {
+	parts := strings.Split(group, ",")
+	if len(parts) != n {
+		return nil, false
+	}
+	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		if _, err := strconv.ParseUint(p, 10, 16); err != nil {
+			return nil, false
+		}
+		parts[i] = p
+	}
+	return parts, true
+}
@@ -0,0 +1,155 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// replayDir holds samba-tool output recorded for the replay backend, see replayRunner
+const replayDir = "../../testdata/replay"
+
+var updateGolden = flag.Bool("update", false, "rewrite the .golden files under testdata/replay")
+
+// parseGolden is what parsing one replay fixture produces
+type parseGolden struct {
+	// Record is what parseQueryOutput returns, nil when samba-tool reported that nothing exists
+	Record *DNSRecord `json:",omitempty"`
+	// Error is the parseQueryOutput error, empty when it succeeded
+	Error string `json:",omitempty"`
+	// Node is every record parseNodeRecords finds in the output
+	Node []DNSRecord
+}
+
+// replayArgs recovers the samba-tool arguments a fixture was recorded for, the inverse of replayKey
+func replayArgs(t *testing.T, key string) []string {
+	t.Helper()
+	parts := strings.Split(key, "_")
+	for i, part := range parts {
+		arg, err := url.PathUnescape(part)
+		if err != nil {
+			t.Fatalf("fixture %s: %v", key, err)
+		}
+		parts[i] = arg
+	}
+	if replayKey(parts) != key {
+		t.Fatalf("fixture %s is not named the way replayKey names it (%s)", key, replayKey(parts))
+	}
+	return parts
+}
+
+// TestParseReplayFixtures runs every recorded dns query output through the parser and compares
+// the result with its .golden file; run with -update to rewrite them
+func TestParseReplayFixtures(t *testing.T) {
+	outputs, err := filepath.Glob(filepath.Join(replayDir, "*.out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) == 0 {
+		t.Fatalf("no fixtures in %s", replayDir)
+	}
+
+	for _, path := range outputs {
+		key := strings.TrimSuffix(filepath.Base(path), ".out")
+		t.Run(key, func(t *testing.T) {
+			args := replayArgs(t, key)
+			if len(args) != 6 || args[0] != "dns" || args[1] != "query" {
+				t.Skipf("not a dns query fixture: %v", args)
+			}
+			server, zone, name, recordType := args[2], args[3], args[4], args[5]
+
+			output, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got parseGolden
+			if stderr, err := os.ReadFile(strings.TrimSuffix(path, ".out") + ".err"); err == nil {
+				// A failed query; the only failure the provider reads as a result is a missing record
+				if !isNotExistError(errors.New(string(stderr))) {
+					t.Fatalf("recorded failure is not a missing record: %s", stderr)
+				}
+			} else {
+				record, err := parseQueryOutput(string(output), server, zone, name, recordType)
+				if err != nil {
+					got.Error = err.Error()
+				}
+				got.Record = record
+			}
+			if got.Node, err = parseNodeRecords(string(output), server, zone, name); err != nil {
+				t.Fatalf("parseNodeRecords: %v", err)
+			}
+
+			encoded, err := json.MarshalIndent(got, "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded = append(encoded, '\n')
+
+			golden := strings.TrimSuffix(path, ".out") + ".golden"
+			if *updateGolden {
+				if err := os.WriteFile(golden, encoded, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run go test -run TestParseReplayFixtures -update to create it)", err)
+			}
+			if !bytes.Equal(encoded, want) {
+				t.Errorf("parsed %s differs from %s\ngot:\n%s\nwant:\n%s", filepath.Base(path), filepath.Base(golden), encoded, want)
+			}
+		})
+	}
+}
+
+// FuzzParseRecordLine feeds arbitrary record lines to the parser, seeded with the record lines of the
+// replay fixtures. parseRecordLine must not panic, and a record it accepts must come back unchanged
+// when its value is printed into a record line again and reparsed
+func FuzzParseRecordLine(f *testing.F) {
+	outputs, err := filepath.Glob(filepath.Join(replayDir, "*.out"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range outputs {
+		output, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "Name=") {
+				f.Add(line)
+			}
+		}
+	}
+	// Lines that broke the parser before it had a tokenizer
+	f.Add(`TXT: "v=spf1 (mx) a:host(1)" (flags=f0, serial=1, ttl=300)`)
+	f.Add(`CNAME: host(1).example.com. (flags=f0, serial=1, ttl=300)`)
+	f.Add("A:\t192.168.1.1   (flags=f0,serial=1,ttl=60)")
+
+	f.Fuzz(func(t *testing.T, line string) {
+		record, err := parseRecordLine(line)
+		if err != nil {
+			return
+		}
+		if _, err := tokenizeRecordValue(record.Value); err != nil {
+			t.Fatalf("value %q of %q does not tokenize: %v", record.Value, line, err)
+		}
+		again := fmt.Sprintf("%s: %s (flags=%x, serial=1, ttl=%d)", record.Type, record.Value, record.Flags, record.TTL)
+		reparsed, err := parseRecordLine(again)
+		if err != nil {
+			t.Fatalf("%q parsed from %q does not parse again: %v", again, line, err)
+		}
+		if reparsed.Type != record.Type || reparsed.Value != record.Value || reparsed.TTL != record.TTL || reparsed.Flags != record.Flags {
+			t.Fatalf("%q parsed to %+v, printed as %q it parses to %+v", line, *record, again, *reparsed)
+		}
+	})
+}
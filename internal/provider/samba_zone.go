@@ -0,0 +1,302 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// zoneFilter narrows a zone enumeration
+// Filters are applied while the output streams, so non-matching records are never kept and
+// the walk stops as soon as Offset+Limit matches have been seen
+type zoneFilter struct {
+	NamePrefix string
+	Types      map[string]bool // upper-case types; empty means all
+	Offset     int
+	Limit      int // 0 means no limit
+	// Exclude drops records it returns true for
+	Exclude func(DNSRecord) bool
+}
+
+func (f zoneFilter) matches(r DNSRecord) bool {
+	if len(f.Types) > 0 && !f.Types[r.Type] {
+		return false
+	}
+	if f.Exclude != nil && f.Exclude(r) {
+		return false
+	}
+	return strings.HasPrefix(strings.ToLower(r.Name), strings.ToLower(f.NamePrefix))
+}
+
+// errEnumerationDone stops a filtered walk once enough records were collected
+var errEnumerationDone = errors.New("enumeration limit reached")
+
+// ListZoneRecords enumerates every record in a zone
+// samba-tool only lists direct children of the queried node, so nodes
+// reporting children are queried in turn until the whole tree is walked
+func (c *SambaClient) ListZoneRecords(server, zone string) ([]DNSRecord, error) {
+	records, _, err := c.ListZoneRecordsFiltered(server, zone, zoneFilter{})
+	return records, err
+}
+
+// ListZoneRecordsFiltered is ListZoneRecords restricted by filter
+// more reports whether records beyond the limit exist
+func (c *SambaClient) ListZoneRecordsFiltered(server, zone string, filter zoneFilter) (records []DNSRecord, more bool, err error) {
+	skipped := 0
+	pending := []string{"@"}
+	visited := map[string]bool{}
+
+	for len(pending) > 0 {
+		node := pending[0]
+		pending = pending[1:]
+		if visited[node] {
+			continue
+		}
+		visited[node] = true
+
+		// Zone apexes can list tens of thousands of records, so the output is parsed as it streams
+		parser := newZoneParser(server, zone, node, func(r DNSRecord) error {
+			if !filter.matches(r) {
+				return nil
+			}
+			if skipped < filter.Offset {
+				skipped++
+				return nil
+			}
+			if filter.Limit > 0 && len(records) == filter.Limit {
+				more = true
+				return errEnumerationDone
+			}
+			records = append(records, r)
+			return nil
+		})
+		err := c.streamCommand(parser.feed, "dns", "query", server, zone, node, "ALL")
+		if errors.Is(err, errEnumerationDone) {
+			return records, true, nil
+		}
+		if err != nil {
+			if isNotExistError(err) {
+				continue
+			}
+			return nil, false, fmt.Errorf("failed to enumerate %s: %w", node, err)
+		}
+
+		pending = append(pending, parser.descend...)
+	}
+
+	return records, more, nil
+}
+
+// zoneParser parses samba-tool dns query ALL output for a node and its children, one line at a time
+// It collects the records found and the names of child nodes that have children of their own
+// Example output:
+//
+//	Name=, Records=1, Children=0
+//	  SOA: serial=1, refresh=900, retry=600, expire=86400, minttl=3600, ns=dc1.example.com., email=hostmaster.example.com. (flags=600000f0, serial=1, ttl=3600)
+//	Name=_tcp, Records=0, Children=4
+//	Name=web, Records=1, Children=0
+//	  A: 192.168.1.100 (flags=f0, serial=2, ttl=900)
+type zoneParser struct {
+	server, zone, base string
+	current            string
+
+	// keep receives every parsed record
+	keep    func(DNSRecord) error
+	descend []string
+}
+
+func newZoneParser(server, zone, base string, keep func(DNSRecord) error) *zoneParser {
+	return &zoneParser{server: server, zone: zone, base: base, current: base, keep: keep}
+}
+
+// feed parses one line of output
+func (p *zoneParser) feed(line string) error {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+	if strings.HasPrefix(line, "Name=") {
+		label, children := parseNodeHeader(line)
+		p.current = joinNodeName(label, p.base)
+		if children > 0 && p.current != p.base {
+			p.descend = append(p.descend, p.current)
+		}
+		return nil
+	}
+	record, err := parseRecordLine(line)
+	if err != nil {
+		return err
+	}
+	record.Server = p.server
+	record.Zone = p.zone
+	record.Name = p.current
+	return p.keep(*record)
+}
+
+// parseNodeHeader extracts the label and child count from "Name=web, Records=1, Children=0"
+func parseNodeHeader(line string) (label string, children int) {
+	for _, field := range strings.Split(line, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+		switch key {
+		case "Name":
+			label = value
+		case "Children":
+			children, _ = strconv.Atoi(value)
+		}
+	}
+	return label, children
+}
+
+// nodeChildren returns the child count samba-tool reports for the queried node itself ("Name=, ..."),
+// and false when the output has no such header
+func nodeChildren(output string) (int, bool) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "Name=") {
+			continue
+		}
+		if label, children := parseNodeHeader(line); label == "" {
+			return children, true
+		}
+	}
+	return 0, false
+}
+
+// joinNodeName combines a child label with the name of the node it was listed under
+func joinNodeName(label, base string) string {
+	if label == "" {
+		return base
+	}
+	if base == "@" || base == "" {
+		return label
+	}
+	return label + "." + base
+}
+
+// soaFields are the SOA fields in the order samba-tool dns add/update expects them
+var soaFields = []string{"ns", "email", "serial", "refresh", "retry", "expire", "minttl"}
+
+// QuerySOA returns the fields of a zone's SOA record
+func (c *SambaClient) QuerySOA(server, zone string) (map[string]string, error) {
+	record, err := c.QueryRecord(server, zone, "@", "SOA")
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("zone %s has no SOA record", zone)
+	}
+	return parseSOAValue(record.Value)
+}
+
+// parseSOAValue parses "serial=1, refresh=900, retry=600, expire=86400, minttl=3600, ns=dc1.example.com., email=hostmaster.example.com."
+func parseSOAValue(value string) (map[string]string, error) {
+	fields := make(map[string]string)
+	for _, field := range strings.Split(value, ",") {
+		key, v, found := strings.Cut(strings.TrimSpace(field), "=")
+		if found {
+			fields[key] = v
+		}
+	}
+	for _, key := range soaFields {
+		if fields[key] == "" {
+			return nil, fmt.Errorf("unexpected SOA format: %s", value)
+		}
+	}
+	return fields, nil
+}
+
+// formatSOAData renders SOA fields as samba-tool record data: "ns email serial refresh retry expire minttl"
+func formatSOAData(fields map[string]string) string {
+	parts := make([]string, len(soaFields))
+	for i, key := range soaFields {
+		parts[i] = fields[key]
+	}
+	return strings.Join(parts, " ")
+}
+
+// BumpSerial increments a zone's SOA serial by one and returns the new serial
+// Serials wrap modulo 2^32 as RFC 1982 defines
+func (c *SambaClient) BumpSerial(server, zone string) (uint32, error) {
+	fields, err := c.QuerySOA(server, zone)
+	if err != nil {
+		return 0, err
+	}
+	serial, err := strconv.ParseUint(fields["serial"], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("unexpected SOA serial %q: %w", fields["serial"], err)
+	}
+
+	next := make(map[string]string, len(fields))
+	for k, v := range fields {
+		next[k] = v
+	}
+	bumped := uint32(serial) + 1
+	next["serial"] = strconv.FormatUint(uint64(bumped), 10)
+
+	if _, err := c.runCommand("dns", "update", server, zone, "@", "SOA", formatSOAData(fields), formatSOAData(next)); err != nil {
+		return 0, err
+	}
+	return bumped, nil
+}
+
+// isZoneNotExistError reports whether a samba-tool error means the zone does not exist
+func isZoneNotExistError(err error) bool {
+	return strings.Contains(err.Error(), "WERR_DNS_ERROR_ZONE_DOES_NOT_EXIST")
+}
+
+// CreateZone creates a zone stored in the given directory partition ("domain" or "forest")
+func (c *SambaClient) CreateZone(server, zone, partition string) error {
+	_, err := c.runCommand("dns", "zonecreate", server, zone, "--dns-directory-partition="+partition)
+	c.info.forget(zoneInfoKey(server, zone))
+	c.info.forget(zoneListKey(server))
+	return err
+}
+
+// ListZones returns the names of the zones hosted on server
+// The list is fetched once per provider run, and again after the provider creates or deletes a zone
+// Example output:
+//
+//	2 zone(s) found
+//
+//	pszZoneName                 : example.com
+//	Flags                       : DNS_RPC_ZONE_DSINTEGRATED DNS_RPC_ZONE_UPDATE_SECURE
+//	ZoneType                    : DNS_ZONE_TYPE_PRIMARY
+func (c *SambaClient) ListZones(server string) ([]string, error) {
+	fields, err := c.info.get(zoneListKey(server), func() (map[string]string, error) {
+		output, err := c.runCommand("dns", "zonelist", server)
+		if err != nil {
+			return nil, err
+		}
+		zones := make(map[string]string)
+		for _, line := range strings.Split(output, "\n") {
+			key, value, found := strings.Cut(line, ":")
+			if found && strings.TrimSpace(key) == "pszZoneName" {
+				zones[strings.TrimSpace(value)] = ""
+			}
+		}
+		return zones, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	zones := make([]string, 0, len(fields))
+	for zone := range fields {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	return zones, nil
+}
+
+// DeleteZone removes a zone and every record in it
+func (c *SambaClient) DeleteZone(server, zone string) error {
+	_, err := c.runCommand("dns", "zonedelete", server, zone)
+	c.info.forget(zoneInfoKey(server, zone))
+	c.info.forget(zoneListKey(server))
+	if err != nil && isZoneNotExistError(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// selfTestProbeName is the TXT record the self-test writes and deletes in the sandbox zone
+const selfTestProbeName = "_sambadns-selftest"
+
+// selfTestCheck is one line of the readiness report
+type selfTestCheck struct {
+	name   string
+	status string // PASS, FAIL or SKIP
+	detail string
+}
+
+// selfTest runs the readiness checks in order; a failed check skips the ones depending on it
+type selfTest struct {
+	checks []selfTestCheck
+	failed bool
+}
+
+func (t *selfTest) pass(name, format string, args ...interface{}) {
+	t.checks = append(t.checks, selfTestCheck{name, "PASS", fmt.Sprintf(format, args...)})
+}
+
+func (t *selfTest) fail(name string, err error) {
+	t.checks = append(t.checks, selfTestCheck{name, "FAIL", err.Error()})
+	t.failed = true
+}
+
+func (t *selfTest) skip(name, reason string) {
+	t.checks = append(t.checks, selfTestCheck{name, "SKIP", reason})
+}
+
+// RunSelfTest checks that the environment can run the provider and prints a readiness report, returning the exit status
+// It is configured from the environment: SAMBADNS_USERNAME and SAMBADNS_PASSWORD as for the provider,
+// SAMBADNS_SERVER naming the DC, and optionally SAMBADNS_SELFTEST_ZONE naming a sandbox zone in which
+// a probe record is created and deleted. SAMBADNS_BACKEND_FILE tests against a file backend instead
+func RunSelfTest(stdout io.Writer) int {
+	t := &selfTest{}
+	server := os.Getenv("SAMBADNS_SERVER")
+	sandbox := strings.TrimSuffix(os.Getenv("SAMBADNS_SELFTEST_ZONE"), ".")
+
+	backendFile := os.Getenv("SAMBADNS_BACKEND_FILE")
+	c, err := clientFromEnv(backendFile)
+	switch {
+	case err != nil:
+		t.fail("configuration", err)
+	case server == "":
+		t.fail("configuration", fmt.Errorf("SAMBADNS_SERVER must name the DC to test"))
+	case backendFile != "":
+		t.pass("configuration", "file backend %s, server %s", backendFile, server)
+	default:
+		t.pass("configuration", "user %s, server %s", c.Username, server)
+	}
+
+	if t.failed {
+		t.skip("samba-tool", "configuration is incomplete")
+	} else if backendFile != "" {
+		t.skip("samba-tool", "not used by the file backend")
+	} else if err := checkCommandAvailable(c.Command, c.runner); err != nil {
+		t.fail("samba-tool", err)
+	} else {
+		t.pass("samba-tool", "%s is available", strings.Join(c.Command, " "))
+	}
+
+	if t.failed {
+		t.skip("authentication", "an earlier check failed")
+	} else if info, err := c.ServerInfo(server); err != nil {
+		t.fail("authentication", err)
+	} else if version := decodeServerVersion(info["dwVersion"]); version != "" {
+		t.pass("authentication", "%s answered serverinfo (version %s)", info["pszServerName"], version)
+	} else {
+		t.pass("authentication", "%s answered serverinfo", info["pszServerName"])
+	}
+
+	var zones []string
+	if t.failed {
+		t.skip("zones", "an earlier check failed")
+	} else if zones, err = c.ListZones(server); err != nil {
+		t.fail("zones", err)
+	} else {
+		t.pass("zones", "%d zone(s): %s", len(zones), strings.Join(zones, ", "))
+	}
+
+	switch {
+	case sandbox == "":
+		t.skip("probe write", "set SAMBADNS_SELFTEST_ZONE to create and delete a probe record")
+	case t.failed:
+		t.skip("probe write", "an earlier check failed")
+	default:
+		if err := selfTestProbe(c, server, sandbox, zones); err != nil {
+			t.fail("probe write", err)
+		} else {
+			t.pass("probe write", "created, read back and deleted TXT %s in %s", selfTestProbeName, sandbox)
+		}
+	}
+
+	for _, check := range t.checks {
+		fmt.Fprintf(stdout, "%-4s  %-14s  %s\n", check.status, check.name, check.detail)
+	}
+	if t.failed {
+		fmt.Fprintln(stdout, "NOT READY")
+		return 1
+	}
+	fmt.Fprintln(stdout, "READY")
+	return 0
+}
+
+// selfTestProbe creates a TXT record in the sandbox zone, reads it back and deletes it again
+func selfTestProbe(c *SambaClient, server, sandbox string, zones []string) error {
+	found := false
+	for _, z := range zones {
+		if strings.EqualFold(z, sandbox) {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("sandbox zone %s is not hosted on %s", sandbox, server)
+	}
+
+	probe := DNSRecord{
+		Server: server,
+		Zone:   sandbox,
+		Name:   selfTestProbeName,
+		Type:   "TXT",
+		Value:  fmt.Sprintf("sambadns-selftest-%d", time.Now().Unix()),
+	}
+	if err := c.CreateRecord(probe); err != nil {
+		return fmt.Errorf("create: %w", err)
+	}
+	record, err := c.QueryRecord(server, sandbox, probe.Name, probe.Type)
+	if err == nil && record == nil {
+		err = fmt.Errorf("the record was created but a query does not return it")
+	}
+	if deleteErr := c.DeleteRecord(probe); deleteErr != nil {
+		return fmt.Errorf("delete %s (remove it by hand): %w", joinFQDN(probe.Name, sandbox), deleteErr)
+	}
+	if err != nil {
+		return fmt.Errorf("read back: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// Modes of the provider serial_check argument
+const (
+	serialCheckOff  = "off"
+	serialCheckWarn = "warn"
+	serialCheckFail = "fail"
+)
+
+// serialCheck compares a zone's SOA serial before and after each record write
+// Every write to a zone the DNS server loads moves its serial. On the BIND9 DLZ backend a serial
+// that stays put usually means the write went to a partition the server does not serve
+type serialCheck struct {
+	// fail turns an unchanged serial into an error instead of a warning
+	fail bool
+}
+
+// newSerialCheck returns the check for a serial_check mode, nil when it is off
+func newSerialCheck(mode string) *serialCheck {
+	switch mode {
+	case serialCheckWarn:
+		return &serialCheck{}
+	case serialCheckFail:
+		return &serialCheck{fail: true}
+	}
+	return nil
+}
+
+// checkedZone returns the zone a record write goes to, when the client checks serials
+// Record writes are dns add, delete and update followed by server, zone, name and type
+func (c *SambaClient) checkedZone(args []string) (string, bool) {
+	if c.serials == nil || len(args) < 6 || args[0] != "dns" {
+		return "", false
+	}
+	switch args[1] {
+	case "add", "delete", "update":
+		return args[3], true
+	}
+	return "", false
+}
+
+// zoneSerial reads a zone's SOA serial past the query cache, which a write invalidates anyway
+func (c *SambaClient) zoneSerial(server, zone string) (string, error) {
+	output, err := c.execCommand([]string{"dns", "query", server, zone, "@", "SOA"})
+	if err != nil {
+		return "", err
+	}
+	record, err := parseQueryOutput(output, server, zone, "@", "SOA")
+	if err != nil {
+		return "", err
+	}
+	if record == nil {
+		return "", fmt.Errorf("zone %s has no SOA record", zone)
+	}
+	fields, err := parseSOAValue(record.Value)
+	if err != nil {
+		return "", err
+	}
+	return fields["serial"], nil
+}
+
+// verifySerial reports a write after which the zone serial has not moved
+// The write has happened by then: in fail mode the error fails the operation, so grouped writes roll back
+func (c *SambaClient) verifySerial(args []string, before string) error {
+	server, zone := args[2], args[3]
+	after, err := c.zoneSerial(server, zone)
+	if err == nil && after != before {
+		return nil
+	}
+	target := args[4] + " " + args[5]
+	var msg string
+	if err != nil {
+		msg = fmt.Sprintf("the SOA serial of zone %s on %s could not be read back after samba-tool dns %s %s: %v", zone, server, args[1], target, err)
+	} else {
+		msg = fmt.Sprintf("samba-tool dns %s %s left the SOA serial of zone %s on %s at %s; "+
+			"the write may have gone to a partition the DNS server does not load", args[1], target, zone, server, before)
+	}
+	if c.serials.fail {
+		return errors.New(msg + " (serial_check = \"fail\")")
+	}
+	c.serialWarnings.add(msg)
+	return nil
+}
+
+// serialWarnings collects the unchanged serials one resource operation ran into
+type serialWarnings struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// add records one unchanged serial
+func (w *serialWarnings) add(msg string) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.messages = append(w.messages, msg)
+}
+
+// serialWarningsKey is the context key of the operation's serialWarnings
+type serialWarningsKey struct{}
+
+// serialWarningsFrom returns the serial warnings attached to the context, if any
+func serialWarningsFrom(ctx context.Context) *serialWarnings {
+	w, _ := ctx.Value(serialWarningsKey{}).(*serialWarnings)
+	return w
+}
+
+// withSerialWarnings returns a copy of the client that reports unchanged serials to w
+func (c *SambaClient) withSerialWarnings(w *serialWarnings) *SambaClient {
+	clone := *c
+	clone.serialWarnings = w
+	return &clone
+}
+
+// reportSerialWarnings adds a warning listing the unchanged serials recorded in w
+func reportSerialWarnings(w *serialWarnings, diags diag.Diagnostics) diag.Diagnostics {
+	if w == nil {
+		return diags
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.messages) == 0 {
+		return diags
+	}
+	return append(diags, diag.Diagnostic{
+		Severity: diag.Warning,
+		Summary:  "Zone serial did not change after a write",
+		Detail: strings.Join(w.messages, "\n") + "\n\nCheck which partition the zone is stored in (sambadns_zone exports it) " +
+			"and which one the DNS server loads. Set serial_check = \"fail\" to stop the apply instead.",
+	})
+}
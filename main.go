@@ -2,20 +2,34 @@ package main
 
 import (
 	"flag"
+	"os"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/plugin"
 	"github.com/devindice/terraform-provider-sambadns/internal/provider"
 )
 
+// Registry documentation is rendered from the schema descriptions, examples included, into docs/
+//go:generate go run github.com/hashicorp/terraform-plugin-docs/cmd/tfplugindocs@v0.16.0 generate --provider-name sambadns
+
 // version is set at build time via ldflags
 var version = "dev"
 
 func main() {
-	var debugMode bool
+	// One-off operations run through the provider's client instead of serving the plugin
+	if len(os.Args) > 1 && provider.IsCLICommand(os.Args[1]) {
+		os.Exit(provider.RunCLI(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	var debugMode, selfTest bool
 
 	flag.BoolVar(&debugMode, "debug", false, "set to true to run the provider with support for debuggers like delve")
+	flag.BoolVar(&selfTest, "selftest", false, "check the configuration from the environment and print a readiness report")
 	flag.Parse()
 
+	if selfTest {
+		os.Exit(provider.RunSelfTest(os.Stdout))
+	}
+
 	opts := &plugin.ServeOpts{
 		ProviderFunc: provider.New(version),
 	}